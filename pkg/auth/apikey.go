@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// keyNameRegex matches the optional name in front of a key
+var keyNameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// APIKeyVerifier checks static API keys loaded from a file
+type APIKeyVerifier struct {
+	// keys holds SHA-256 digests so comparisons take constant time
+	// regardless of key length
+	keys map[[sha256.Size]byte]string
+}
+
+// LoadAPIKeys reads API keys from a file.
+//
+// Each non-empty line holds one key, optionally prefixed with a name and a
+// colon ("ci-agent:s3cr3t"). Names consist of letters, digits, '-' and '_';
+// the key is everything after the first colon and may contain colons. An
+// unnamed key that contains a colon must be given a name, since its first
+// part would otherwise be read as one. Lines starting with '#' are ignored.
+func LoadAPIKeys(path string) (*APIKeyVerifier, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open API key file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	v := &APIKeyVerifier{keys: make(map[[sha256.Size]byte]string)}
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name := fmt.Sprintf("key-%d", lineNum)
+		key := line
+		if before, after, found := strings.Cut(line, ":"); found {
+			if !keyNameRegex.MatchString(strings.TrimSpace(before)) {
+				return nil, fmt.Errorf("invalid API key name on line %d of %s: keys containing ':' must be prefixed with a name of letters, digits, '-' or '_'", lineNum, path)
+			}
+			name = strings.TrimSpace(before)
+			key = strings.TrimSpace(after)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("empty API key on line %d of %s", lineNum, path)
+		}
+
+		v.keys[sha256.Sum256([]byte(key))] = name
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read API key file %s: %w", path, err)
+	}
+
+	if len(v.keys) == 0 {
+		return nil, fmt.Errorf("no API keys found in %s", path)
+	}
+
+	return v, nil
+}
+
+// Verify returns the name of the matching key
+func (v *APIKeyVerifier) Verify(key string) (string, error) {
+	digest := sha256.Sum256([]byte(key))
+
+	var name string
+	found := false
+	for candidate, candidateName := range v.keys {
+		if subtle.ConstantTimeCompare(digest[:], candidate[:]) == 1 {
+			name = candidateName
+			found = true
+		}
+	}
+
+	if !found {
+		return "", ErrInvalidCredentials
+	}
+	return name, nil
+}
@@ -0,0 +1,178 @@
+// Package auth provides request authentication for the HTTP MCP transports.
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidCredentials is returned when presented credentials are rejected
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Rejection reasons used for logging and counting
+const (
+	ReasonMissingCredentials = "missing_credentials"
+	ReasonInvalidAPIKey      = "invalid_api_key"
+	ReasonInvalidJWT         = "invalid_jwt"
+)
+
+// Principal identifies an authenticated caller
+type Principal struct {
+	// Method is "api_key" or "jwt"
+	Method string
+	// Name is the API key name or the JWT subject
+	Name string
+}
+
+type principalKey struct{}
+
+// PrincipalFromContext returns the authenticated caller stored in ctx
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Authenticator validates API keys and JWT bearer tokens on HTTP requests
+type Authenticator struct {
+	apiKeys *APIKeyVerifier
+	jwt     *JWTVerifier
+	logger  *slog.Logger
+
+	mu       sync.Mutex
+	rejected map[string]uint64
+}
+
+// NewAuthenticator creates an authenticator. Either verifier may be nil, but
+// not both.
+func NewAuthenticator(apiKeys *APIKeyVerifier, jwt *JWTVerifier, logger *slog.Logger) (*Authenticator, error) {
+	if apiKeys == nil && jwt == nil {
+		return nil, errors.New("at least one of API keys or JWKS must be configured")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Authenticator{
+		apiKeys:  apiKeys,
+		jwt:      jwt,
+		logger:   logger,
+		rejected: make(map[string]uint64),
+	}, nil
+}
+
+// Middleware rejects requests without valid credentials with 401
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, reason, err := a.authenticate(r)
+		if err != nil {
+			a.reject(w, r, reason, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), principalKey{}, principal)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// authenticate extracts and verifies credentials from the request.
+// API keys may be sent in X-API-Key or as a bearer token; bearer tokens that
+// look like JWTs are verified against the JWKS when one is configured.
+func (a *Authenticator) authenticate(r *http.Request) (Principal, string, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return a.verifyAPIKey(key)
+	}
+
+	fields := strings.Fields(r.Header.Get("Authorization"))
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "bearer") {
+		return Principal{}, ReasonMissingCredentials, errors.New("no bearer token or API key")
+	}
+	token := fields[1]
+
+	if a.jwt != nil && strings.Count(token, ".") == 2 {
+		claims, err := a.jwt.Verify(token)
+		if err != nil {
+			return Principal{}, ReasonInvalidJWT, err
+		}
+		return Principal{Method: "jwt", Name: claims.Subject}, "", nil
+	}
+
+	return a.verifyAPIKey(token)
+}
+
+func (a *Authenticator) verifyAPIKey(key string) (Principal, string, error) {
+	if a.apiKeys == nil {
+		return Principal{}, ReasonInvalidAPIKey, errors.New("API key authentication is not enabled")
+	}
+	name, err := a.apiKeys.Verify(key)
+	if err != nil {
+		return Principal{}, ReasonInvalidAPIKey, err
+	}
+	return Principal{Method: "api_key", Name: name}, "", nil
+}
+
+// reject counts and logs a failed authentication and writes the 401 response
+func (a *Authenticator) reject(w http.ResponseWriter, r *http.Request, reason string, err error) {
+	a.mu.Lock()
+	a.rejected[reason]++
+	total := a.rejected[reason]
+	a.mu.Unlock()
+
+	remote := r.RemoteAddr
+	if host, _, splitErr := net.SplitHostPort(remote); splitErr == nil {
+		remote = host
+	}
+
+	a.logger.Warn("Rejected unauthenticated MCP request",
+		"remote_addr", remote,
+		"method", r.Method,
+		"path", r.URL.Path,
+		"reason", reason,
+		"error", err,
+		"count", total)
+
+	w.Header().Set("WWW-Authenticate", `Bearer realm="redfish-mcp"`)
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// Rejections returns the number of rejected requests per reason
+func (a *Authenticator) Rejections() map[string]uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	counts := make(map[string]uint64, len(a.rejected))
+	for reason, n := range a.rejected {
+		counts[reason] = n
+	}
+	return counts
+}
+
+// ReportRejections logs the running rejection totals after each interval in
+// which requests were rejected, until ctx is cancelled
+func (a *Authenticator) ReportRejections(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var reported uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			counts := a.Rejections()
+			var total uint64
+			for _, n := range counts {
+				total += n
+			}
+			if total == reported {
+				continue
+			}
+			reported = total
+			a.logger.Warn("Rejected MCP requests since startup", "total", total, "by_reason", counts)
+		}
+	}
+}
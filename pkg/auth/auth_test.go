@@ -0,0 +1,283 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed + "." + b64(sig)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signed + "." + b64(sig)
+}
+
+func TestAPIKeyVerifier(t *testing.T) {
+	path := writeFile(t, "keys", "# agents\nci-agent: secret-one\n\nsecret-two\n")
+
+	v, err := LoadAPIKeys(path)
+	if err != nil {
+		t.Fatalf("LoadAPIKeys failed: %v", err)
+	}
+
+	if name, err := v.Verify("secret-one"); err != nil || name != "ci-agent" {
+		t.Errorf("Expected ci-agent, got %q (err: %v)", name, err)
+	}
+	if _, err := v.Verify("secret-two"); err != nil {
+		t.Errorf("Expected unnamed key to verify: %v", err)
+	}
+	if _, err := v.Verify("wrong"); err == nil {
+		t.Error("Expected unknown key to be rejected")
+	}
+
+	if _, err := LoadAPIKeys(writeFile(t, "empty", "# nothing\n")); err == nil {
+		t.Error("Expected error for file without keys")
+	}
+}
+
+func TestAPIKeyWithColon(t *testing.T) {
+	v, err := LoadAPIKeys(writeFile(t, "keys", "bastion: dG9rZW4=:c2VjcmV0\n"))
+	if err != nil {
+		t.Fatalf("LoadAPIKeys failed: %v", err)
+	}
+	if name, err := v.Verify("dG9rZW4=:c2VjcmV0"); err != nil || name != "bastion" {
+		t.Errorf("Expected bastion for a named key containing ':', got %q (err: %v)", name, err)
+	}
+
+	// An unnamed key with a colon would be split at the wrong place
+	if _, err := LoadAPIKeys(writeFile(t, "unnamed", "dG9rZW4=:c2VjcmV0\n")); err == nil {
+		t.Error("Expected error for an unnamed key containing ':'")
+	}
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %v", err)
+	}
+
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA", "kid": "rsa-1", "alg": "RS256", "use": "sig",
+				"n": b64(rsaKey.N.Bytes()),
+				"e": b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC", "crv": "P-256",
+				"x": b64(ecKey.X.FillBytes(make([]byte, 32))),
+				"y": b64(ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+		},
+	})
+	v, err := LoadJWKS(writeFile(t, "jwks.json", string(jwks)), "https://issuer.example", "redfish-mcp")
+	if err != nil {
+		t.Fatalf("LoadJWKS failed: %v", err)
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+	valid := map[string]interface{}{
+		"sub": "agent-1", "iss": "https://issuer.example", "aud": []string{"redfish-mcp"}, "exp": exp,
+	}
+
+	claims, err := v.Verify(signRS256(t, rsaKey, "rsa-1", valid))
+	if err != nil {
+		t.Fatalf("Valid RS256 token rejected: %v", err)
+	}
+	if claims.Subject != "agent-1" {
+		t.Errorf("Expected subject agent-1, got %q", claims.Subject)
+	}
+
+	if _, err := v.Verify(signES256(t, ecKey, valid)); err != nil {
+		t.Errorf("Valid ES256 token rejected: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"wrong kid", signRS256(t, rsaKey, "other", valid)},
+		{"expired", signRS256(t, rsaKey, "rsa-1", map[string]interface{}{
+			"iss": "https://issuer.example", "aud": "redfish-mcp", "exp": time.Now().Add(-time.Hour).Unix(),
+		})},
+		{"missing exp", signRS256(t, rsaKey, "rsa-1", map[string]interface{}{
+			"iss": "https://issuer.example", "aud": "redfish-mcp",
+		})},
+		{"wrong audience", signRS256(t, rsaKey, "rsa-1", map[string]interface{}{
+			"iss": "https://issuer.example", "aud": "other", "exp": exp,
+		})},
+		{"wrong issuer", signRS256(t, rsaKey, "rsa-1", map[string]interface{}{
+			"iss": "https://evil.example", "aud": "redfish-mcp", "exp": exp,
+		})},
+		{"malformed", "not-a-jwt"},
+	}
+	for _, tt := range tests {
+		if _, err := v.Verify(tt.token); err == nil {
+			t.Errorf("%s: expected token to be rejected", tt.name)
+		}
+	}
+}
+
+func TestAuthenticatorMiddleware(t *testing.T) {
+	keys, err := LoadAPIKeys(writeFile(t, "keys", "agent: secret\n"))
+	if err != nil {
+		t.Fatalf("LoadAPIKeys failed: %v", err)
+	}
+
+	authenticator, err := NewAuthenticator(keys, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewAuthenticator failed: %v", err)
+	}
+
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok || principal.Name != "agent" {
+			t.Errorf("Expected principal agent in context, got %+v", principal)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		status int
+	}{
+		{"bearer", "Authorization", "Bearer secret", http.StatusNoContent},
+		{"x-api-key", "X-API-Key", "secret", http.StatusNoContent},
+		{"missing", "", "", http.StatusUnauthorized},
+		{"wrong key", "X-API-Key", "nope", http.StatusUnauthorized},
+		{"jwt without jwks", "Authorization", "Bearer a.b.c", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		if tt.header != "" {
+			req.Header.Set(tt.header, tt.value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, rec.Code)
+		}
+	}
+
+	rejections := authenticator.Rejections()
+	if rejections[ReasonMissingCredentials] != 1 {
+		t.Errorf("Expected 1 missing credentials rejection, got %d", rejections[ReasonMissingCredentials])
+	}
+	if rejections[ReasonInvalidAPIKey] != 2 {
+		t.Errorf("Expected 2 invalid API key rejections, got %d", rejections[ReasonInvalidAPIKey])
+	}
+}
+
+// lockedBuffer is a log sink that can be read while a goroutine writes to it
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestReportRejections(t *testing.T) {
+	keys, err := LoadAPIKeys(writeFile(t, "keys", "agent: secret\n"))
+	if err != nil {
+		t.Fatalf("LoadAPIKeys failed: %v", err)
+	}
+	var logs lockedBuffer
+	authenticator, err := NewAuthenticator(keys, nil, slog.New(slog.NewTextHandler(&logs, nil)))
+	if err != nil {
+		t.Fatalf("NewAuthenticator failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go authenticator.ReportRejections(ctx, 5*time.Millisecond)
+
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/mcp", nil))
+
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(logs.String(), "Rejected MCP requests since startup") {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected rejection totals to be logged, got:\n%s", logs.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !strings.Contains(logs.String(), "total=1") {
+		t.Errorf("Expected a running total of 1, got:\n%s", logs.String())
+	}
+
+	// Totals are not repeated while nothing new is rejected
+	time.Sleep(50 * time.Millisecond)
+	if n := strings.Count(logs.String(), "Rejected MCP requests since startup"); n != 1 {
+		t.Errorf("Expected totals to be logged once, got %d", n)
+	}
+}
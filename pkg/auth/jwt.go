@@ -0,0 +1,312 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // register hashes used by crypto.Hash.New
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// clockSkew is the leeway applied to exp and nbf checks
+const clockSkew = 30 * time.Second
+
+// jwk is a single JSON Web Key as found in a JWKS document
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// verificationKey is a parsed public key from the JWKS
+type verificationKey struct {
+	kid string
+	alg string
+	key crypto.PublicKey
+}
+
+// JWTVerifier verifies JWT bearer tokens against a local JWKS file
+type JWTVerifier struct {
+	keys     []verificationKey
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// Claims holds the registered claims checked by JWTVerifier
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  Audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+}
+
+// Audience is the JWT "aud" claim, which may be a string or an array
+type Audience []string
+
+// UnmarshalJSON accepts both the string and array forms of "aud"
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("aud must be a string or array of strings: %w", err)
+	}
+	*a = multiple
+	return nil
+}
+
+// LoadJWKS reads a JWKS file and returns a verifier for tokens signed by its
+// keys. Empty issuer or audience values disable the corresponding check.
+func LoadJWKS(path, issuer, audience string) (*JWTVerifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file %s: %w", path, err)
+	}
+
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON in JWKS file %s: %w", path, err)
+	}
+
+	v := &JWTVerifier{
+		issuer:   issuer,
+		audience: audience,
+		now:      time.Now,
+	}
+
+	for i, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key at index %d in %s: %w", i, path, err)
+		}
+		v.keys = append(v.keys, verificationKey{kid: k.Kid, alg: k.Alg, key: key})
+	}
+
+	if len(v.keys) == 0 {
+		return nil, fmt.Errorf("no signing keys found in %s", path)
+	}
+
+	return v, nil
+}
+
+// publicKey converts the JWK into a Go public key
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("RSA exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC x coordinate: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC y coordinate: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("EC point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+	}
+}
+
+// Verify checks the token signature and registered claims
+func (v *JWTVerifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed JWT", ErrInvalidCredentials)
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid JWT header encoding", ErrInvalidCredentials)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("%w: invalid JWT header", ErrInvalidCredentials)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid JWT signature encoding", ErrInvalidCredentials)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	if !v.verifySignature(header.Alg, header.Kid, signed, signature) {
+		return nil, fmt.Errorf("%w: JWT signature verification failed", ErrInvalidCredentials)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid JWT payload encoding", ErrInvalidCredentials)
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: invalid JWT claims", ErrInvalidCredentials)
+	}
+
+	if err := v.checkClaims(&claims); err != nil {
+		return nil, err
+	}
+
+	return &claims, nil
+}
+
+// verifySignature tries every key matching kid and alg
+func (v *JWTVerifier) verifySignature(alg, kid string, signed, signature []byte) bool {
+	hash, ok := hashForAlg(alg)
+	if !ok {
+		return false
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	for _, k := range v.keys {
+		if kid != "" && k.kid != "" && k.kid != kid {
+			continue
+		}
+		if k.alg != "" && k.alg != alg {
+			continue
+		}
+
+		switch pub := k.key.(type) {
+		case *rsa.PublicKey:
+			switch alg[:2] {
+			case "RS":
+				if rsa.VerifyPKCS1v15(pub, hash, digest, signature) == nil {
+					return true
+				}
+			case "PS":
+				if rsa.VerifyPSS(pub, hash, digest, signature, nil) == nil {
+					return true
+				}
+			}
+		case *ecdsa.PublicKey:
+			if alg[:2] != "ES" {
+				continue
+			}
+			// JWS encodes ECDSA signatures as fixed-width r||s
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			if len(signature) != 2*size {
+				continue
+			}
+			r := new(big.Int).SetBytes(signature[:size])
+			s := new(big.Int).SetBytes(signature[size:])
+			if ecdsa.Verify(pub, digest, r, s) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// checkClaims validates expiry, not-before, issuer and audience
+func (v *JWTVerifier) checkClaims(claims *Claims) error {
+	now := v.now()
+
+	if claims.ExpiresAt == 0 {
+		return fmt.Errorf("%w: JWT has no exp claim", ErrInvalidCredentials)
+	}
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(clockSkew)) {
+		return fmt.Errorf("%w: JWT expired", ErrInvalidCredentials)
+	}
+	if claims.NotBefore != 0 && now.Add(clockSkew).Before(time.Unix(claims.NotBefore, 0)) {
+		return fmt.Errorf("%w: JWT not yet valid", ErrInvalidCredentials)
+	}
+
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return fmt.Errorf("%w: unexpected JWT issuer %q", ErrInvalidCredentials, claims.Issuer)
+	}
+	if v.audience != "" && !slices.Contains(claims.Audience, v.audience) {
+		return fmt.Errorf("%w: JWT audience does not include %q", ErrInvalidCredentials, v.audience)
+	}
+
+	return nil
+}
+
+// hashForAlg maps a JWS algorithm name to its hash function
+func hashForAlg(alg string) (crypto.Hash, bool) {
+	if len(alg) != 5 {
+		return 0, false
+	}
+	switch alg[:2] {
+	case "RS", "PS", "ES":
+	default:
+		return 0, false
+	}
+	switch alg[2:] {
+	case "256":
+		return crypto.SHA256, true
+	case "384":
+		return crypto.SHA384, true
+	case "512":
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, fmt.Errorf("missing value")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
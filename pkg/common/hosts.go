@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"log/slog"
 	"os"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/config"
 	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/redfish"
@@ -12,10 +14,14 @@ import (
 
 // HostManager manages both static and discovered Redfish hosts
 type HostManager struct {
-	staticHosts     []config.HostConfig
-	discoveredHosts []redfish.DiscoveredHost
+	staticHosts []config.HostConfig
+	// discoveredHosts holds discovered hosts by service UUID, USN or
+	// address, whichever is known first
+	discoveredHosts map[string]redfish.DiscoveredHost
 	mu              sync.RWMutex
 	logger          *slog.Logger
+	// now is replaced in tests
+	now func() time.Time
 }
 
 // NewHostManager creates a new host manager
@@ -25,7 +31,9 @@ func NewHostManager(logger *slog.Logger) *HostManager {
 	}
 
 	hm := &HostManager{
-		logger: logger,
+		discoveredHosts: make(map[string]redfish.DiscoveredHost),
+		logger:          logger,
+		now:             time.Now,
 	}
 
 	// Load static hosts from environment
@@ -54,18 +62,71 @@ func (hm *HostManager) loadStaticHosts() {
 	hm.logger.Info("Loaded static hosts", "count", len(hosts))
 }
 
-// UpdateDiscoveredHosts updates the list of discovered hosts
+// UpdateDiscoveredHosts adds or renews discovered hosts and drops those
+// whose advertisement has expired. Hosts missing from a search are kept
+// until their max-age elapses.
 func (hm *HostManager) UpdateDiscoveredHosts(hosts []redfish.DiscoveredHost) {
+	now := hm.now()
+
+	hm.mu.Lock()
+	for _, host := range hosts {
+		if host.ExpiresAt.IsZero() {
+			host.ExpiresAt = now.Add(redfish.DefaultSSDPMaxAge)
+		}
+		hm.discoveredHosts[discoveryKey(host)] = host
+	}
+	expired := hm.pruneExpiredLocked(now)
+	count := len(hm.discoveredHosts)
+	hm.mu.Unlock()
+
+	hm.logger.Info("Updated discovered hosts", "count", count, "expired", expired)
+}
+
+// RemoveDiscoveredHost drops a discovered host that announced its departure,
+// identified by its UUID, USN or address
+func (hm *HostManager) RemoveDiscoveredHost(host redfish.DiscoveredHost) {
 	hm.mu.Lock()
-	hm.discoveredHosts = hosts
+	removed, found := hm.discoveredHosts[discoveryKey(host)]
+	delete(hm.discoveredHosts, discoveryKey(host))
 	hm.mu.Unlock()
 
-	hm.logger.Info("Updated discovered hosts", "count", len(hosts))
+	if found {
+		hm.logger.Info("Removed discovered host", "address", removed.Address, "usn", removed.USN)
+	}
+}
+
+// pruneExpiredLocked removes expired discovered hosts and returns how many
+// were removed. hm.mu must be held for writing.
+func (hm *HostManager) pruneExpiredLocked(now time.Time) int {
+	expired := 0
+	for key, host := range hm.discoveredHosts {
+		if !now.Before(host.ExpiresAt) {
+			hm.logger.Info("Discovered host expired", "address", host.Address, "usn", host.USN)
+			delete(hm.discoveredHosts, key)
+			expired++
+		}
+	}
+	return expired
+}
+
+// discoveryKey identifies a discovered service across searches and
+// discovery methods, following it when its address changes
+func discoveryKey(host redfish.DiscoveredHost) string {
+	switch {
+	case host.UUID != "":
+		return "uuid:" + strings.ToLower(host.UUID)
+	case host.USN != "":
+		return host.USN
+	default:
+		return host.Address
+	}
 }
 
 // GetHosts returns the merged list of static and discovered hosts
 // Static hosts take precedence over discovered hosts with the same address
 func (hm *HostManager) GetHosts() []config.HostConfig {
+	now := hm.now()
+
 	hm.mu.RLock()
 	defer hm.mu.RUnlock()
 
@@ -77,10 +138,15 @@ func (hm *HostManager) GetHosts() []config.HostConfig {
 
 	// Add discovered hosts (only if not already present)
 	for _, discovered := range hm.discoveredHosts {
+		// Expired hosts are skipped here and removed on the next update
+		if !now.Before(discovered.ExpiresAt) {
+			continue
+		}
 		if _, exists := allHosts[discovered.Address]; !exists {
 			// Convert discovered host to config format
 			host := config.HostConfig{
 				Address: discovered.Address,
+				Port:    discovered.Port,
 				// Use defaults for other fields since discovered hosts don't provide them
 			}
 			allHosts[discovered.Address] = host
@@ -0,0 +1,95 @@
+package common
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/redfish"
+)
+
+func newTestHostManager(t *testing.T, now *time.Time) *HostManager {
+	t.Helper()
+	t.Setenv("REDFISH_HOSTS", `[{"address": "10.0.0.1"}]`)
+
+	hm := NewHostManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	hm.now = func() time.Time { return *now }
+	return hm
+}
+
+func TestDiscoveredHostsUsePort(t *testing.T) {
+	now := time.Now()
+	hm := newTestHostManager(t, &now)
+
+	hm.UpdateDiscoveredHosts([]redfish.DiscoveredHost{
+		{Address: "10.0.0.5", Port: 8443, ExpiresAt: now.Add(time.Minute)},
+		// Static hosts take precedence
+		{Address: "10.0.0.1", Port: 9443, ExpiresAt: now.Add(time.Minute)},
+	})
+
+	host, found := hm.GetHostByAddress("10.0.0.5")
+	if !found || host.Port != 8443 {
+		t.Errorf("Expected discovered host on port 8443, got %+v (found=%v)", host, found)
+	}
+	host, found = hm.GetHostByAddress("10.0.0.1")
+	if !found || host.Port != 0 {
+		t.Errorf("Expected static host without port, got %+v (found=%v)", host, found)
+	}
+}
+
+func TestDiscoveredHostsExpire(t *testing.T) {
+	now := time.Now()
+	hm := newTestHostManager(t, &now)
+
+	hm.UpdateDiscoveredHosts([]redfish.DiscoveredHost{
+		{Address: "10.0.0.5", USN: "uuid:a::urn:dmtf-org:service:redfish-rest:1", ExpiresAt: now.Add(time.Minute)},
+		{Address: "10.0.0.6", USN: "uuid:b::urn:dmtf-org:service:redfish-rest:1", ExpiresAt: now.Add(time.Hour)},
+	})
+
+	// A later search that misses both hosts keeps them until they expire
+	now = now.Add(30 * time.Second)
+	hm.UpdateDiscoveredHosts(nil)
+	if got := len(hm.GetAddresses()); got != 3 {
+		t.Fatalf("Expected 3 hosts before expiry, got %d", got)
+	}
+
+	now = now.Add(time.Minute)
+	if _, found := hm.GetHostByAddress("10.0.0.5"); found {
+		t.Error("Expected 10.0.0.5 to have expired")
+	}
+	if _, found := hm.GetHostByAddress("10.0.0.6"); !found {
+		t.Error("Expected 10.0.0.6 to still be present")
+	}
+
+	// Renewing a service under a new address replaces the old address
+	hm.UpdateDiscoveredHosts([]redfish.DiscoveredHost{
+		{Address: "10.0.0.7", USN: "uuid:b::urn:dmtf-org:service:redfish-rest:1", ExpiresAt: now.Add(time.Hour)},
+	})
+	if _, found := hm.GetHostByAddress("10.0.0.6"); found {
+		t.Error("Expected 10.0.0.6 to be replaced by 10.0.0.7")
+	}
+	if got := len(hm.discoveredHosts); got != 1 {
+		t.Errorf("Expected expired hosts to be removed, %d remain", got)
+	}
+}
+
+func TestDiscoveredHostsMergeByUUID(t *testing.T) {
+	now := time.Now()
+	hm := newTestHostManager(t, &now)
+
+	// Found by SSDP, then by a sweep that reports the UUID in lower case
+	hm.UpdateDiscoveredHosts([]redfish.DiscoveredHost{
+		{Address: "fe80::5%eth1", UUID: "1A2B3C4D-0000-1111-2222-333344445555", ExpiresAt: now.Add(time.Hour)},
+	})
+	hm.UpdateDiscoveredHosts([]redfish.DiscoveredHost{
+		{Address: "10.0.0.5", UUID: "1a2b3c4d-0000-1111-2222-333344445555", ExpiresAt: now.Add(time.Hour)},
+	})
+
+	if got := len(hm.discoveredHosts); got != 1 {
+		t.Errorf("Expected the service to be tracked once, got %d entries", got)
+	}
+	if _, found := hm.GetHostByAddress("10.0.0.5"); !found {
+		t.Error("Expected the latest address to be listed")
+	}
+}
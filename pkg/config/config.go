@@ -4,18 +4,26 @@ package config
 import (
 	"errors"
 	"fmt"
+	"math"
+	"net"
 	"slices"
 	"strings"
+
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/redfish"
 )
 
 // AuthMethod represents Redfish authentication methods
 type AuthMethod string
 
 const (
-	AuthMethodBasic   AuthMethod = "basic"
-	AuthMethodSession AuthMethod = "session"
+	AuthMethodBasic       AuthMethod = "basic"
+	AuthMethodSession     AuthMethod = "session"
+	AuthMethodCertificate AuthMethod = "certificate"
 )
 
+// validAuthMethods lists the accepted auth_method values
+var validAuthMethods = []AuthMethod{AuthMethodBasic, AuthMethodSession, AuthMethodCertificate}
+
 // MCPTransport represents MCP transport types
 type MCPTransport string
 
@@ -33,8 +41,34 @@ type HostConfig struct {
 	Password        string `json:"password,omitempty"`
 	AuthMethod      string `json:"auth_method,omitempty"`
 	TLSServerCACert string `json:"tls_server_ca_cert,omitempty"`
+	// TLSServerCAMerge overrides RedfishConfig.TLSServerCAMerge for this host
+	TLSServerCAMerge *bool `json:"tls_server_ca_merge,omitempty"`
+	// TLSClientCert and TLSClientKey are presented to the BMC; they are
+	// required when AuthMethod is "certificate"
+	TLSClientCert string `json:"tls_client_cert,omitempty"`
+	TLSClientKey  string `json:"tls_client_key,omitempty"`
+	// TLSPinSHA256 is the SHA-256 fingerprint (hex or base64) of the BMC's
+	// leaf certificate or SPKI; it replaces CA verification for this host
+	TLSPinSHA256 string `json:"tls_pin_sha256,omitempty"`
+	// TLSTrustOnFirstUse pins the SPKI presented on first connection when no
+	// TLSPinSHA256 is configured
+	TLSTrustOnFirstUse bool `json:"tls_trust_on_first_use,omitempty"`
+	// InsecureSkipVerify overrides RedfishConfig.InsecureSkipVerify for this host
+	InsecureSkipVerify *bool `json:"insecure_skip_verify,omitempty"`
+	// TLSMinVersion overrides RedfishConfig.TLSMinVersion for this host
+	TLSMinVersion string `json:"tls_min_version,omitempty"`
+	// TLSServerName is sent as SNI and used to verify the certificate
+	// hostname, for BMCs addressed by IP
+	TLSServerName string `json:"tls_server_name,omitempty"`
+	// Retry overrides RedfishConfig.Retry for this host
+	Retry *RetryConfig `json:"retry,omitempty"`
+	// MaxInFlight overrides RedfishConfig.MaxInFlight for this host
+	MaxInFlight int `json:"max_in_flight,omitempty"`
 }
 
+// validTLSVersions lists the accepted tls_min_version values
+var validTLSVersions = []string{"1.0", "1.1", "1.2", "1.3"}
+
 // Validate validates the host configuration
 func (h *HostConfig) Validate() error {
 	if h.Address == "" {
@@ -45,8 +79,95 @@ func (h *HostConfig) Validate() error {
 		return fmt.Errorf("port must be between 1 and 65535, got: %d", h.Port)
 	}
 
-	if h.AuthMethod != "" && h.AuthMethod != string(AuthMethodBasic) && h.AuthMethod != string(AuthMethodSession) {
-		return fmt.Errorf("invalid auth_method: %s. Must be one of: %s, %s", h.AuthMethod, AuthMethodBasic, AuthMethodSession)
+	if h.AuthMethod != "" && !slices.Contains(validAuthMethods, AuthMethod(h.AuthMethod)) {
+		return fmt.Errorf("invalid auth_method: %s. Must be one of: %v", h.AuthMethod, validAuthMethods)
+	}
+
+	if (h.TLSClientCert == "") != (h.TLSClientKey == "") {
+		return errors.New("tls_client_cert and tls_client_key must be set together")
+	}
+
+	if h.AuthMethod == string(AuthMethodCertificate) && h.TLSClientCert == "" {
+		return fmt.Errorf("auth_method %s requires tls_client_cert and tls_client_key", AuthMethodCertificate)
+	}
+
+	if h.TLSMinVersion != "" && !slices.Contains(validTLSVersions, h.TLSMinVersion) {
+		return fmt.Errorf("invalid tls_min_version: %s. Must be one of: %v", h.TLSMinVersion, validTLSVersions)
+	}
+
+	if h.TLSPinSHA256 != "" {
+		if _, err := redfish.ParsePin(h.TLSPinSHA256); err != nil {
+			return fmt.Errorf("invalid tls_pin_sha256: %w", err)
+		}
+	}
+
+	if h.MaxInFlight < 0 {
+		return fmt.Errorf("max_in_flight cannot be negative, got: %d", h.MaxInFlight)
+	}
+
+	if h.Retry != nil {
+		if err := h.Retry.Validate(); err != nil {
+			return fmt.Errorf("invalid retry configuration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// RetryConfig controls how failed Redfish requests are retried. Delays are in
+// seconds. Unset fields inherit from the global configuration, then from the
+// client defaults.
+type RetryConfig struct {
+	MaxRetries    *int     `json:"max_retries,omitempty"`
+	InitialDelay  *float64 `json:"initial_delay,omitempty"`
+	MaxDelay      *float64 `json:"max_delay,omitempty"`
+	BackoffFactor *float64 `json:"backoff_factor,omitempty"`
+	// Jitter is one of "none", "full" or "equal"
+	Jitter string `json:"jitter,omitempty"`
+	// Deadline bounds the total time of a request including retries
+	Deadline *float64 `json:"deadline,omitempty"`
+}
+
+// validJitterModes lists the accepted jitter values
+var validJitterModes = []string{"none", "full", "equal"}
+
+// Validate validates the retry configuration
+func (r *RetryConfig) Validate() error {
+	if r.MaxRetries != nil && (*r.MaxRetries < 0 || *r.MaxRetries > 10) {
+		return fmt.Errorf("max_retries must be between 0 and 10, got: %d", *r.MaxRetries)
+	}
+
+	for name, value := range map[string]*float64{
+		"initial_delay":  r.InitialDelay,
+		"max_delay":      r.MaxDelay,
+		"deadline":       r.Deadline,
+		"backoff_factor": r.BackoffFactor,
+	} {
+		if value != nil && (math.IsNaN(*value) || math.IsInf(*value, 0)) {
+			return fmt.Errorf("%s must be a finite number, got: %g", name, *value)
+		}
+	}
+
+	for name, value := range map[string]*float64{
+		"initial_delay": r.InitialDelay,
+		"max_delay":     r.MaxDelay,
+		"deadline":      r.Deadline,
+	} {
+		if value != nil && *value < 0 {
+			return fmt.Errorf("%s cannot be negative, got: %g", name, *value)
+		}
+	}
+
+	if r.InitialDelay != nil && r.MaxDelay != nil && *r.MaxDelay < *r.InitialDelay {
+		return fmt.Errorf("max_delay (%g) must not be less than initial_delay (%g)", *r.MaxDelay, *r.InitialDelay)
+	}
+
+	if r.BackoffFactor != nil && *r.BackoffFactor < 1 {
+		return fmt.Errorf("backoff_factor must be at least 1, got: %g", *r.BackoffFactor)
+	}
+
+	if r.Jitter != "" && !slices.Contains(validJitterModes, r.Jitter) {
+		return fmt.Errorf("invalid jitter: %s. Must be one of: %v", r.Jitter, validJitterModes)
 	}
 
 	return nil
@@ -60,9 +181,46 @@ type RedfishConfig struct {
 	Username           string       `json:"username"`
 	Password           string       `json:"password"`
 	TLSServerCACert    string       `json:"tls_server_ca_cert,omitempty"`
+	TLSServerCAMerge   bool         `json:"tls_server_ca_merge,omitempty"`
+	TLSPinStoreFile    string       `json:"tls_pin_store_file,omitempty"`
 	InsecureSkipVerify bool         `json:"insecure_skip_verify"`
+	TLSMinVersion      string       `json:"tls_min_version,omitempty"`
 	DiscoveryEnabled   bool         `json:"discovery_enabled"`
 	DiscoveryInterval  int          `json:"discovery_interval"`
+	// DiscoveryInterfaces restricts SSDP searches to network interfaces,
+	// given by name or by a CIDR matching their addresses; empty searches
+	// via the default route
+	DiscoveryInterfaces []string `json:"discovery_interfaces,omitempty"`
+	// DiscoveryIPv6 also searches the IPv6 link-local and site-local SSDP
+	// groups
+	DiscoveryIPv6 bool `json:"discovery_ipv6,omitempty"`
+	// DiscoverySweepRanges are CIDRs whose addresses are probed for a
+	// Redfish service root on every discovery pass, for networks that
+	// block multicast
+	DiscoverySweepRanges []string `json:"discovery_sweep_ranges,omitempty"`
+	// DiscoverySweepConcurrency is the number of addresses probed at once;
+	// 0 uses the default
+	DiscoverySweepConcurrency int `json:"discovery_sweep_concurrency,omitempty"`
+	// DiscoverySweepTimeout is how long, in seconds, each probe may take;
+	// 0 uses the default
+	DiscoverySweepTimeout int `json:"discovery_sweep_timeout,omitempty"`
+	// SessionIdleTimeout is how long, in seconds, a pooled BMC session may
+	// stay unused before it is logged out; 0 uses the default
+	SessionIdleTimeout int `json:"session_idle_timeout,omitempty"`
+	// CircuitBreakerThreshold is the number of consecutive network failures
+	// after which calls to a host fail fast; 0 uses the default
+	CircuitBreakerThreshold int `json:"circuit_breaker_threshold,omitempty"`
+	// CircuitBreakerCooldown is how long, in seconds, a host fails fast
+	// before a probe request is let through; 0 uses the default
+	CircuitBreakerCooldown int `json:"circuit_breaker_cooldown,omitempty"`
+	// MaxInFlight is the number of concurrent requests sent to one BMC;
+	// 0 uses the default
+	MaxInFlight int `json:"max_in_flight,omitempty"`
+	// QueueTimeout is how long, in seconds, a request waits for a free
+	// slot before failing; 0 uses the default
+	QueueTimeout int `json:"queue_timeout,omitempty"`
+	// Retry is the default retry policy for all hosts
+	Retry RetryConfig `json:"retry,omitempty"`
 }
 
 // Validate validates the Redfish configuration
@@ -71,27 +229,142 @@ func (r *RedfishConfig) Validate() error {
 		return fmt.Errorf("port must be between 1 and 65535, got: %d", r.Port)
 	}
 
-	if r.AuthMethod != string(AuthMethodBasic) && r.AuthMethod != string(AuthMethodSession) {
-		return fmt.Errorf("invalid auth_method: %s. Must be one of: %s, %s", r.AuthMethod, AuthMethodBasic, AuthMethodSession)
+	if !slices.Contains(validAuthMethods, AuthMethod(r.AuthMethod)) {
+		return fmt.Errorf("invalid auth_method: %s. Must be one of: %v", r.AuthMethod, validAuthMethods)
+	}
+
+	if r.TLSMinVersion != "" && !slices.Contains(validTLSVersions, r.TLSMinVersion) {
+		return fmt.Errorf("invalid tls_min_version: %s. Must be one of: %v", r.TLSMinVersion, validTLSVersions)
 	}
 
 	if r.DiscoveryInterval < 1 {
 		return fmt.Errorf("discovery interval must be positive, got: %d", r.DiscoveryInterval)
 	}
 
+	for _, iface := range r.DiscoveryInterfaces {
+		if err := validateDiscoveryInterface(iface); err != nil {
+			return err
+		}
+	}
+
+	for _, cidr := range r.DiscoverySweepRanges {
+		if err := validateSweepRange(cidr); err != nil {
+			return err
+		}
+	}
+
+	if r.DiscoverySweepConcurrency < 0 {
+		return fmt.Errorf("discovery sweep concurrency cannot be negative, got: %d", r.DiscoverySweepConcurrency)
+	}
+
+	if r.DiscoverySweepTimeout < 0 {
+		return fmt.Errorf("discovery sweep timeout cannot be negative, got: %d", r.DiscoverySweepTimeout)
+	}
+
+	if r.SessionIdleTimeout < 0 {
+		return fmt.Errorf("session idle timeout cannot be negative, got: %d", r.SessionIdleTimeout)
+	}
+
+	if r.CircuitBreakerThreshold < 0 {
+		return fmt.Errorf("circuit breaker threshold cannot be negative, got: %d", r.CircuitBreakerThreshold)
+	}
+
+	if r.CircuitBreakerCooldown < 0 {
+		return fmt.Errorf("circuit breaker cooldown cannot be negative, got: %d", r.CircuitBreakerCooldown)
+	}
+
+	if r.MaxInFlight < 0 {
+		return fmt.Errorf("max in flight cannot be negative, got: %d", r.MaxInFlight)
+	}
+
+	if r.QueueTimeout < 0 {
+		return fmt.Errorf("queue timeout cannot be negative, got: %d", r.QueueTimeout)
+	}
+
+	if err := r.Retry.Validate(); err != nil {
+		return fmt.Errorf("invalid retry configuration: %w", err)
+	}
+
 	for i, host := range r.Hosts {
 		if err := host.Validate(); err != nil {
 			return fmt.Errorf("invalid host configuration at index %d: %w", i, err)
 		}
+		// Hosts inheriting certificate auth must still bring their own certificate
+		if host.AuthMethod == "" && r.AuthMethod == string(AuthMethodCertificate) && host.TLSClientCert == "" {
+			return fmt.Errorf("invalid host configuration at index %d: auth_method %s requires tls_client_cert and tls_client_key", i, AuthMethodCertificate)
+		}
 	}
 
 	return nil
 }
 
+// validateDiscoveryInterface checks an interface name or CIDR
+func validateDiscoveryInterface(iface string) error {
+	if strings.TrimSpace(iface) == "" {
+		return fmt.Errorf("discovery interface cannot be empty")
+	}
+	if strings.Contains(iface, "/") {
+		if _, _, err := net.ParseCIDR(iface); err != nil {
+			return fmt.Errorf("invalid discovery interface CIDR %s: %w", iface, err)
+		}
+	}
+	return nil
+}
+
+// maxSweepHostBits caps a sweep range at 65536 addresses
+const maxSweepHostBits = 16
+
+// validateSweepRange checks a CIDR to sweep
+func validateSweepRange(cidr string) error {
+	_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+	if err != nil {
+		return fmt.Errorf("invalid discovery sweep range %s: %w", cidr, err)
+	}
+	ones, bits := ipNet.Mask.Size()
+	if bits-ones > maxSweepHostBits {
+		return fmt.Errorf("discovery sweep range %s is too large, at most %d addresses are allowed", cidr, 1<<maxSweepHostBits)
+	}
+	return nil
+}
+
 // MCPConfig represents MCP server configuration
 type MCPConfig struct {
 	Transport MCPTransport `json:"transport"`
 	LogLevel  string       `json:"log_level"`
+	Host      string       `json:"host,omitempty"`
+	Port      int          `json:"port,omitempty"`
+	// Path is the endpoint the streamable-http transport is served on
+	Path string `json:"path,omitempty"`
+	// Stateless disables Mcp-Session-Id tracking and stream resumption for
+	// the streamable-http transport
+	Stateless bool `json:"stateless,omitempty"`
+	// AuthAPIKeysFile lists static API keys accepted by the HTTP transports
+	AuthAPIKeysFile string `json:"auth_api_keys_file,omitempty"`
+	// AuthJWKSFile is a local JWKS used to verify JWT bearer tokens
+	AuthJWKSFile    string `json:"auth_jwks_file,omitempty"`
+	AuthJWTIssuer   string `json:"auth_jwt_issuer,omitempty"`
+	AuthJWTAudience string `json:"auth_jwt_audience,omitempty"`
+	// TLSCertFile and TLSKeyFile enable HTTPS on the HTTP transports
+	TLSCertFile string `json:"tls_cert_file,omitempty"`
+	TLSKeyFile  string `json:"tls_key_file,omitempty"`
+	// TLSClientCAFile enables mutual TLS, requiring client certificates
+	// signed by one of the CAs in the bundle
+	TLSClientCAFile string `json:"tls_client_ca_file,omitempty"`
+}
+
+// TLSEnabled reports whether the HTTP listener is configured for TLS
+func (m *MCPConfig) TLSEnabled() bool {
+	return m.TLSCertFile != "" && m.TLSKeyFile != ""
+}
+
+// AuthEnabled reports whether HTTP transport authentication is configured
+func (m *MCPConfig) AuthEnabled() bool {
+	return m.AuthAPIKeysFile != "" || m.AuthJWKSFile != ""
+}
+
+// IsHTTP reports whether the configured transport is served over HTTP
+func (m *MCPConfig) IsHTTP() bool {
+	return m.Transport == MCPTransportSSE || m.Transport == MCPTransportStreamableHTTP
 }
 
 // Validate validates the MCP configuration
@@ -106,6 +379,26 @@ func (m *MCPConfig) Validate() error {
 		return fmt.Errorf("invalid log_level: %s. Must be one of: %v", m.LogLevel, validLogLevels)
 	}
 
+	if m.IsHTTP() && (m.Port < 1 || m.Port > 65535) {
+		return fmt.Errorf("port must be between 1 and 65535, got: %d", m.Port)
+	}
+
+	if m.Transport == MCPTransportStreamableHTTP && !strings.HasPrefix(m.Path, "/") {
+		return fmt.Errorf("path must start with '/', got: %q", m.Path)
+	}
+
+	if m.AuthJWKSFile == "" && (m.AuthJWTIssuer != "" || m.AuthJWTAudience != "") {
+		return errors.New("auth_jwt_issuer and auth_jwt_audience require auth_jwks_file")
+	}
+
+	if (m.TLSCertFile == "") != (m.TLSKeyFile == "") {
+		return errors.New("tls_cert_file and tls_key_file must be set together")
+	}
+
+	if m.TLSClientCAFile != "" && !m.TLSEnabled() {
+		return errors.New("tls_client_ca_file requires tls_cert_file and tls_key_file")
+	}
+
 	m.LogLevel = strings.ToUpper(m.LogLevel)
 	return nil
 }
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"math"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -113,3 +115,127 @@ func TestInvalidConfig(t *testing.T) {
 		t.Fatal("Invalid config passed validation")
 	}
 }
+
+func TestCertificateAuthValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    HostConfig
+		wantErr bool
+	}{
+		{"cert and key", HostConfig{Address: "bmc", AuthMethod: "certificate", TLSClientCert: "c.pem", TLSClientKey: "k.pem"}, false},
+		{"missing cert", HostConfig{Address: "bmc", AuthMethod: "certificate"}, true},
+		{"cert without key", HostConfig{Address: "bmc", AuthMethod: "certificate", TLSClientCert: "c.pem"}, true},
+		{"key without cert", HostConfig{Address: "bmc", TLSClientKey: "k.pem"}, true},
+	}
+
+	for _, tt := range tests {
+		err := tt.host.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+
+	// Hosts inheriting certificate auth from the global setting need a certificate too
+	redfish := &RedfishConfig{
+		Hosts:             []HostConfig{{Address: "bmc"}},
+		Port:              443,
+		AuthMethod:        "certificate",
+		DiscoveryInterval: 30,
+	}
+	if err := redfish.Validate(); err == nil {
+		t.Error("Expected error for host without certificate under global certificate auth")
+	}
+}
+
+func TestPinValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		pin     string
+		wantErr bool
+	}{
+		{"hex", strings.Repeat("ab", 32), false},
+		{"colon hex", strings.TrimSuffix(strings.Repeat("AB:", 32), ":"), false},
+		{"base64", "sha256/" + strings.Repeat("A", 43) + "=", false},
+		{"short hex", strings.Repeat("ab", 20), true},
+		{"garbage", "not-a-pin", true},
+	}
+
+	for _, tt := range tests {
+		err := (&HostConfig{Address: "bmc", TLSPinSHA256: tt.pin}).Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestDiscoveryInterfacesValidation(t *testing.T) {
+	redfish := &RedfishConfig{
+		Port:                443,
+		AuthMethod:          "session",
+		DiscoveryInterval:   30,
+		DiscoveryInterfaces: []string{"eth1", "10.20.0.0/16", "fd00::/64"},
+	}
+	if err := redfish.Validate(); err != nil {
+		t.Fatalf("Valid discovery interfaces failed validation: %v", err)
+	}
+
+	redfish.DiscoveryInterfaces = []string{"10.20.0.0/33"}
+	if err := redfish.Validate(); err == nil {
+		t.Error("Invalid discovery CIDR passed validation")
+	}
+}
+
+func TestDiscoveryInterfacesFromEnv(t *testing.T) {
+	t.Setenv("REDFISH_DISCOVERY_INTERFACES", " eth1, ,10.20.0.0/16")
+	t.Setenv("REDFISH_DISCOVERY_IPV6", "true")
+
+	config, err := loadRedfishConfig()
+	if err != nil {
+		t.Fatalf("loadRedfishConfig failed: %v", err)
+	}
+	if len(config.DiscoveryInterfaces) != 2 || config.DiscoveryInterfaces[0] != "eth1" || config.DiscoveryInterfaces[1] != "10.20.0.0/16" {
+		t.Errorf("Expected [eth1 10.20.0.0/16], got %v", config.DiscoveryInterfaces)
+	}
+	if !config.DiscoveryIPv6 {
+		t.Error("Expected IPv6 discovery enabled")
+	}
+}
+
+func TestDiscoverySweepRangesValidation(t *testing.T) {
+	redfish := &RedfishConfig{
+		Port:                 443,
+		AuthMethod:           "session",
+		DiscoveryInterval:    30,
+		DiscoverySweepRanges: []string{"10.20.0.0/16", "10.30.1.0/24"},
+	}
+	if err := redfish.Validate(); err != nil {
+		t.Fatalf("Valid sweep ranges failed validation: %v", err)
+	}
+
+	for _, cidr := range []string{"10.0.0.0/8", "10.20.0.1"} {
+		redfish.DiscoverySweepRanges = []string{cidr}
+		if err := redfish.Validate(); err == nil {
+			t.Errorf("Invalid sweep range %s passed validation", cidr)
+		}
+	}
+}
+
+func TestRetryConfigRejectsNonFiniteValues(t *testing.T) {
+	for _, value := range []string{"NaN", "Inf", "+Inf", "-inf"} {
+		t.Setenv("REDFISH_RETRY_BACKOFF_FACTOR", value)
+		if _, err := loadRedfishConfig(); err == nil {
+			t.Errorf("Backoff factor %s passed environment parsing", value)
+		}
+	}
+
+	for _, value := range []float64{math.NaN(), math.Inf(1)} {
+		retry := &RetryConfig{BackoffFactor: &value}
+		if err := retry.Validate(); err == nil {
+			t.Errorf("Backoff factor %g passed validation", value)
+		}
+		retry = &RetryConfig{MaxDelay: &value}
+		if err := retry.Validate(); err == nil {
+			t.Errorf("Max delay %g passed validation", value)
+		}
+	}
+}
@@ -3,8 +3,10 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // ConfigError represents configuration validation errors
@@ -110,6 +112,46 @@ func loadRedfishConfig() (*RedfishConfig, error) {
 		return nil, err
 	}
 
+	sessionIdleTimeout, err := getEnvInt("REDFISH_SESSION_IDLE_TIMEOUT", 300, 1, 86400)
+	if err != nil {
+		return nil, err
+	}
+
+	breakerThreshold, err := getEnvInt("REDFISH_CIRCUIT_BREAKER_THRESHOLD", 3, 1, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	breakerCooldown, err := getEnvInt("REDFISH_CIRCUIT_BREAKER_COOLDOWN", 30, 1, 3600)
+	if err != nil {
+		return nil, err
+	}
+
+	maxInFlight, err := getEnvInt("REDFISH_MAX_IN_FLIGHT", 2, 1, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	queueTimeout, err := getEnvInt("REDFISH_QUEUE_TIMEOUT", 30, 1, 3600)
+	if err != nil {
+		return nil, err
+	}
+
+	sweepConcurrency, err := getEnvInt("REDFISH_DISCOVERY_SWEEP_CONCURRENCY", 32, 1, 256)
+	if err != nil {
+		return nil, err
+	}
+
+	sweepTimeout, err := getEnvInt("REDFISH_DISCOVERY_SWEEP_TIMEOUT", 2, 1, 60)
+	if err != nil {
+		return nil, err
+	}
+
+	retryConfig, err := loadRetryConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	config := &RedfishConfig{
 		Hosts:              hosts,
 		Port:               port,
@@ -117,14 +159,66 @@ func loadRedfishConfig() (*RedfishConfig, error) {
 		Username:           getEnv("REDFISH_USERNAME", ""),
 		Password:           getEnv("REDFISH_PASSWORD", ""),
 		TLSServerCACert:    getEnv("REDFISH_SERVER_CA_CERT", ""),
+		TLSServerCAMerge:   getEnvBool("REDFISH_SERVER_CA_MERGE", false),
+		TLSPinStoreFile:    getEnv("REDFISH_TLS_PIN_STORE_FILE", ""),
 		InsecureSkipVerify: getEnvBool("REDFISH_INSECURE_SKIP_VERIFY", false),
+		TLSMinVersion:      getEnv("REDFISH_TLS_MIN_VERSION", "1.2"),
 		DiscoveryEnabled:   getEnvBool("REDFISH_DISCOVERY_ENABLED", false),
 		DiscoveryInterval:  discoveryInterval,
+		DiscoveryIPv6:      getEnvBool("REDFISH_DISCOVERY_IPV6", false),
+		SessionIdleTimeout: sessionIdleTimeout,
+		Retry:              retryConfig,
+
+		CircuitBreakerThreshold: breakerThreshold,
+		CircuitBreakerCooldown:  breakerCooldown,
+		MaxInFlight:             maxInFlight,
+		QueueTimeout:            queueTimeout,
+		DiscoveryInterfaces:     getEnvList("REDFISH_DISCOVERY_INTERFACES"),
+
+		DiscoverySweepRanges:      getEnvList("REDFISH_DISCOVERY_SWEEP_RANGES"),
+		DiscoverySweepConcurrency: sweepConcurrency,
+		DiscoverySweepTimeout:     sweepTimeout,
 	}
 
 	return config, nil
 }
 
+func loadRetryConfig() (RetryConfig, error) {
+	maxRetries, err := getEnvInt("REDFISH_MAX_RETRIES", 3, 0, 10)
+	if err != nil {
+		return RetryConfig{}, err
+	}
+
+	initialDelay, err := getEnvFloat("REDFISH_RETRY_INITIAL_DELAY", 1, 0, 3600)
+	if err != nil {
+		return RetryConfig{}, err
+	}
+
+	maxDelay, err := getEnvFloat("REDFISH_RETRY_MAX_DELAY", 60, 0, 3600)
+	if err != nil {
+		return RetryConfig{}, err
+	}
+
+	backoffFactor, err := getEnvFloat("REDFISH_RETRY_BACKOFF_FACTOR", 2, 1, 10)
+	if err != nil {
+		return RetryConfig{}, err
+	}
+
+	deadline, err := getEnvFloat("REDFISH_RETRY_DEADLINE", 0, 0, 86400)
+	if err != nil {
+		return RetryConfig{}, err
+	}
+
+	return RetryConfig{
+		MaxRetries:    &maxRetries,
+		InitialDelay:  &initialDelay,
+		MaxDelay:      &maxDelay,
+		BackoffFactor: &backoffFactor,
+		Jitter:        getEnv("REDFISH_RETRY_JITTER", "full"),
+		Deadline:      &deadline,
+	}, nil
+}
+
 func loadMCPConfig() (*MCPConfig, error) {
 	transportStr := getEnv("MCP_TRANSPORT", string(MCPTransportStdio))
 	var transport MCPTransport
@@ -141,9 +235,27 @@ func loadMCPConfig() (*MCPConfig, error) {
 		}
 	}
 
+	port, err := getEnvInt("MCP_PORT", 8000, 1, 65535)
+	if err != nil {
+		return nil, err
+	}
+
 	config := &MCPConfig{
 		Transport: transport,
 		LogLevel:  getEnv("MCP_REDFISH_LOG_LEVEL", "INFO"),
+		Host:      getEnv("MCP_HOST", "127.0.0.1"),
+		Port:      port,
+		Path:      getEnv("MCP_PATH", "/mcp"),
+		Stateless: getEnvBool("MCP_STATELESS", false),
+
+		AuthAPIKeysFile: getEnv("MCP_AUTH_API_KEYS_FILE", ""),
+		AuthJWKSFile:    getEnv("MCP_AUTH_JWKS_FILE", ""),
+		AuthJWTIssuer:   getEnv("MCP_AUTH_JWT_ISSUER", ""),
+		AuthJWTAudience: getEnv("MCP_AUTH_JWT_AUDIENCE", ""),
+
+		TLSCertFile:     getEnv("MCP_TLS_CERT_FILE", ""),
+		TLSKeyFile:      getEnv("MCP_TLS_KEY_FILE", ""),
+		TLSClientCAFile: getEnv("MCP_TLS_CLIENT_CA_FILE", ""),
 	}
 
 	return config, nil
@@ -167,6 +279,17 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getEnvList splits a comma-separated variable, dropping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvInt(key string, defaultValue, minVal, maxVal int) (int, error) {
 	value := os.Getenv(key)
 	if value == "" {
@@ -195,3 +318,39 @@ func getEnvInt(key string, defaultValue, minVal, maxVal int) (int, error) {
 
 	return intVal, nil
 }
+
+func getEnvFloat(key string, defaultValue, minVal, maxVal float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	floatVal, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, &ConfigError{
+			Message: fmt.Sprintf("environment variable %s must be a number", key),
+			Cause:   err,
+		}
+	}
+
+	// ParseFloat accepts NaN and Inf, which slip through the range checks
+	if math.IsNaN(floatVal) || math.IsInf(floatVal, 0) {
+		return 0, &ConfigError{
+			Message: fmt.Sprintf("environment variable %s must be a finite number, got: %s", key, value),
+		}
+	}
+
+	if floatVal < minVal {
+		return 0, &ConfigError{
+			Message: fmt.Sprintf("environment variable %s must be >= %g, got: %g", key, minVal, floatVal),
+		}
+	}
+
+	if floatVal > maxVal {
+		return 0, &ConfigError{
+			Message: fmt.Sprintf("environment variable %s must be <= %g, got: %g", key, maxVal, floatVal),
+		}
+	}
+
+	return floatVal, nil
+}
@@ -0,0 +1,181 @@
+package mcp
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/redfish"
+)
+
+const (
+	// defaultBreakerThreshold applies when no threshold is configured
+	defaultBreakerThreshold = 3
+	// defaultBreakerCooldown applies when no cooldown is configured
+	defaultBreakerCooldown = 30 * time.Second
+)
+
+// breakerState is the state of a host's circuit breaker
+type breakerState string
+
+const (
+	// breakerClosed lets calls through
+	breakerClosed breakerState = "closed"
+	// breakerOpen fails calls fast until the cooldown has passed
+	breakerOpen breakerState = "open"
+	// breakerHalfOpen lets a single probe call through
+	breakerHalfOpen breakerState = "half_open"
+)
+
+// BreakerStatus reports a host's circuit breaker in list_servers
+type BreakerStatus struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
+
+// errCircuitOpen is returned for calls rejected by an open breaker
+type errCircuitOpen struct {
+	host     string
+	failures int
+	retryAt  time.Time
+}
+
+func (e *errCircuitOpen) Error() string {
+	return fmt.Sprintf("server %s is unavailable after %d consecutive network failures; calls are rejected until %s",
+		e.host, e.failures, e.retryAt.Format(time.RFC3339))
+}
+
+// circuitBreaker tracks network failures per host so that calls to an
+// unreachable BMC fail fast instead of waiting out every retry
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	logger    *slog.Logger
+	// now is replaced in tests
+	now func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]*hostBreaker
+}
+
+// hostBreaker is the breaker state of one host, guarded by circuitBreaker.mu
+type hostBreaker struct {
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// newCircuitBreaker creates a circuit breaker
+func newCircuitBreaker(threshold int, cooldown time.Duration, logger *slog.Logger) *circuitBreaker {
+	if threshold <= 0 {
+		threshold = defaultBreakerThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		logger:    logger,
+		now:       time.Now,
+		hosts:     make(map[string]*hostBreaker),
+	}
+}
+
+// allow reports whether a call to host may proceed. Once the cooldown of an
+// open breaker has passed, the first caller becomes the half-open probe and
+// the others keep failing fast until the probe's result is recorded.
+func (b *circuitBreaker) allow(host string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	hb := b.hosts[host]
+	if hb == nil {
+		return nil
+	}
+
+	switch hb.state {
+	case breakerOpen:
+		retryAt := hb.openedAt.Add(b.cooldown)
+		if b.now().Before(retryAt) {
+			return &errCircuitOpen{host: host, failures: hb.failures, retryAt: retryAt}
+		}
+		hb.state = breakerHalfOpen
+		b.logger.Info("Circuit breaker half-open, probing host", "host", host)
+		return nil
+	case breakerHalfOpen:
+		return &errCircuitOpen{host: host, failures: hb.failures, retryAt: hb.openedAt.Add(b.cooldown)}
+	default:
+		return nil
+	}
+}
+
+// record updates host's breaker with the outcome of a call. Only network
+// failures count; any response from the BMC, even an error status, shows
+// that it is reachable.
+func (b *circuitBreaker) record(host string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	hb := b.hosts[host]
+	class := redfish.Classify(err)
+
+	switch {
+	case err != nil && class == redfish.ErrorClassCanceled:
+		// The caller gave up, which says nothing about the host. A cancelled
+		// probe hands the half-open slot to the next caller.
+		if hb != nil && hb.state == breakerHalfOpen {
+			hb.state = breakerOpen
+			hb.openedAt = b.now().Add(-b.cooldown)
+		}
+		return
+	case err == nil || class != redfish.ErrorClassNetwork:
+		if hb != nil {
+			if hb.state != breakerClosed {
+				b.logger.Info("Circuit breaker closed, host reachable again", "host", host)
+			}
+			delete(b.hosts, host)
+		}
+		return
+	}
+
+	if hb == nil {
+		hb = &hostBreaker{state: breakerClosed}
+		b.hosts[host] = hb
+	}
+	hb.failures++
+
+	if hb.state == breakerHalfOpen || hb.failures >= b.threshold {
+		if hb.state != breakerOpen {
+			b.logger.Warn("Circuit breaker opened for unreachable host",
+				"host", host,
+				"consecutive_failures", hb.failures,
+				"cooldown", b.cooldown)
+		}
+		hb.state = breakerOpen
+		hb.openedAt = b.now()
+	}
+}
+
+// status returns the breaker status of host for reporting
+func (b *circuitBreaker) status(host string) BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	hb := b.hosts[host]
+	if hb == nil {
+		return BreakerStatus{State: string(breakerClosed)}
+	}
+
+	status := BreakerStatus{
+		State:               string(hb.state),
+		ConsecutiveFailures: hb.failures,
+	}
+	if hb.state == breakerOpen {
+		retryAt := hb.openedAt.Add(b.cooldown)
+		status.RetryAt = &retryAt
+	}
+	return status
+}
@@ -0,0 +1,160 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/config"
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/redfish"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(3, time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	breaker.now = func() time.Time { return now }
+
+	networkErr := &redfish.RedfishError{Message: "connection refused"}
+	host := "10.0.0.1"
+
+	// Failures below the threshold and HTTP errors keep the breaker closed
+	breaker.record(host, networkErr)
+	breaker.record(host, networkErr)
+	if err := breaker.allow(host); err != nil {
+		t.Fatalf("Expected closed breaker after 2 failures, got: %v", err)
+	}
+	breaker.record(host, &redfish.RedfishError{Code: http.StatusNotFound})
+	if status := breaker.status(host); status.State != "closed" || status.ConsecutiveFailures != 0 {
+		t.Fatalf("Expected an HTTP response to reset the breaker, got %+v", status)
+	}
+
+	for i := 0; i < 3; i++ {
+		breaker.record(host, networkErr)
+	}
+	var openErr *errCircuitOpen
+	if err := breaker.allow(host); !errors.As(err, &openErr) {
+		t.Fatalf("Expected open breaker after 3 failures, got: %v", err)
+	}
+	status := breaker.status(host)
+	if status.State != "open" || status.RetryAt == nil || !status.RetryAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("Unexpected open status: %+v", status)
+	}
+
+	// Other hosts are unaffected
+	if err := breaker.allow("10.0.0.2"); err != nil {
+		t.Fatalf("Expected other host to be allowed, got: %v", err)
+	}
+
+	// After the cooldown a single probe is let through
+	now = now.Add(time.Minute)
+	if err := breaker.allow(host); err != nil {
+		t.Fatalf("Expected half-open probe to be allowed, got: %v", err)
+	}
+	if err := breaker.allow(host); err == nil {
+		t.Fatal("Expected concurrent calls to be rejected while probing")
+	}
+
+	// A failed probe reopens the breaker for another cooldown
+	breaker.record(host, networkErr)
+	if err := breaker.allow(host); err == nil {
+		t.Fatal("Expected breaker to reopen after a failed probe")
+	}
+
+	// A cancelled probe hands the slot to the next caller
+	now = now.Add(time.Minute)
+	if err := breaker.allow(host); err != nil {
+		t.Fatalf("Expected probe to be allowed, got: %v", err)
+	}
+	breaker.record(host, context.Canceled)
+	if err := breaker.allow(host); err != nil {
+		t.Fatalf("Expected a new probe after cancellation, got: %v", err)
+	}
+
+	// A successful probe closes the breaker
+	breaker.record(host, nil)
+	if status := breaker.status(host); status.State != "closed" {
+		t.Fatalf("Expected closed breaker after successful probe, got %+v", status)
+	}
+}
+
+func TestListServersReportsBreakerState(t *testing.T) {
+	t.Setenv("REDFISH_HOSTS", `[{"address": "10.0.0.1"}, {"address": "10.0.0.2"}]`)
+	server := newTestServer(t, config.MCPTransportStdio)
+
+	for i := 0; i < defaultBreakerThreshold; i++ {
+		server.breaker.record("10.0.0.1:443", &redfish.RedfishError{Message: "timeout"})
+	}
+
+	_, output, err := server.handleListServers(context.Background(), nil, struct{}{})
+	if err != nil {
+		t.Fatalf("list_servers failed: %v", err)
+	}
+	if state := output.Breakers["10.0.0.1:443"].State; state != "open" {
+		t.Errorf("Expected 10.0.0.1 breaker open, got %q", state)
+	}
+	if state := output.Breakers["10.0.0.2:443"].State; state != "closed" {
+		t.Errorf("Expected 10.0.0.2 breaker closed, got %q", state)
+	}
+
+	_, _, err = server.handleGetResourceData(context.Background(), nil, GetResourceInput{URL: "https://10.0.0.1/redfish/v1/Systems"})
+	var openErr *errCircuitOpen
+	if !errors.As(err, &openErr) {
+		t.Errorf("Expected get_resource_data to fail fast, got: %v", err)
+	}
+}
+
+func TestBreakerOpensOnRetryDeadline(t *testing.T) {
+	// The BMC accepts connections but never answers
+	hang := make(chan struct{})
+	bmc := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hang
+	}))
+	defer bmc.Close()
+	defer close(hang)
+
+	_, port, _ := net.SplitHostPort(bmc.Listener.Addr().String())
+	t.Setenv("REDFISH_HOSTS", `[{"address": "127.0.0.1", "port": `+port+`, "auth_method": "basic",
+		"retry": {"max_retries": 0, "deadline": 0.1}}]`)
+	server := newTestServer(t, config.MCPTransportStdio)
+	server.config.Redfish.InsecureSkipVerify = true
+
+	for i := 0; i < defaultBreakerThreshold; i++ {
+		_, _, err := server.handleGetResourceData(context.Background(), nil, GetResourceInput{URL: "https://127.0.0.1/redfish/v1/"})
+		if err == nil {
+			t.Fatal("Expected the request to exceed its retry deadline")
+		}
+	}
+
+	status := server.breaker.status(net.JoinHostPort("127.0.0.1", port))
+	if status.State != "open" {
+		t.Errorf("Expected breaker open after %d deadline failures, got %+v", defaultBreakerThreshold, status)
+	}
+}
+
+func TestBreakerKeyedByPort(t *testing.T) {
+	server := newTestServer(t, config.MCPTransportStdio)
+	forwarded := config.HostConfig{Address: "10.0.0.1", Port: 8443}
+	direct := config.HostConfig{Address: "10.0.0.1"}
+
+	if key := server.hostKey(direct); key != "10.0.0.1:443" {
+		t.Fatalf("Expected the global port in the key, got %s", key)
+	}
+
+	for i := 0; i < defaultBreakerThreshold; i++ {
+		server.breaker.record(server.hostKey(forwarded), &redfish.RedfishError{Message: "timeout"})
+	}
+
+	var openErr *errCircuitOpen
+	if _, _, err := server.acquireClient(context.Background(), forwarded); !errors.As(err, &openErr) {
+		t.Errorf("Expected the failing port to fail fast, got: %v", err)
+	}
+	if err := server.breaker.allow(server.hostKey(direct)); err != nil {
+		t.Errorf("Expected the other port to be unaffected, got: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/redfish"
+)
+
+// maxDiscoveryTimeout bounds how long one SSDP search waits for responses
+const maxDiscoveryTimeout = 5 * time.Second
+
+// discoveryTimeout returns the response wait of a search for the given
+// discovery interval, keeping each search shorter than the interval
+func discoveryTimeout(interval time.Duration) time.Duration {
+	return min(interval/2, maxDiscoveryTimeout)
+}
+
+// sweepLifetime returns how long hosts found by a sweep stay listed: several
+// discovery intervals, and no less than an SSDP advertisement
+func sweepLifetime(interval time.Duration) time.Duration {
+	return max(3*interval, redfish.DefaultSSDPMaxAge)
+}
+
+// hostDiscoverer finds Redfish services on the network
+type hostDiscoverer interface {
+	DiscoverContext(ctx context.Context) ([]redfish.DiscoveredHost, error)
+}
+
+// runDiscovery searches for Redfish services right away and then every
+// discovery interval, handing the results to the host manager. SSDP and the
+// sweep run on their own schedules, so a slow sweep does not hold up SSDP.
+// It returns when ctx is cancelled.
+func (s *Server) runDiscovery(ctx context.Context) {
+	interval := time.Duration(s.config.Redfish.DiscoveryInterval) * time.Second
+	s.logger.Info("Starting periodic discovery", "interval", interval, "sweep", s.sweep != nil)
+
+	discoverers := map[string]hostDiscoverer{"ssdp": s.discovery}
+	if s.sweep != nil {
+		discoverers["sweep"] = s.sweep
+	}
+
+	var wg sync.WaitGroup
+	for method, discoverer := range discoverers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runDiscoverer(ctx, method, discoverer, interval)
+		}()
+	}
+	wg.Wait()
+
+	s.logger.Info("Stopped periodic discovery")
+}
+
+// runDiscoverer runs a discovery pass right away and then every interval
+// until ctx is cancelled. A pass that outlasts the interval delays only the
+// next pass of the same discoverer.
+func (s *Server) runDiscoverer(ctx context.Context, method string, discoverer hostDiscoverer, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		s.discoverHosts(ctx, method, discoverer)
+		if elapsed := time.Since(start); elapsed > interval && ctx.Err() == nil {
+			s.logger.Warn("Discovery pass took longer than the discovery interval",
+				"method", method,
+				"elapsed", elapsed,
+				"interval", interval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// discoverHosts runs one discovery pass. A failed pass keeps the hosts found
+// previously.
+func (s *Server) discoverHosts(ctx context.Context, method string, discoverer hostDiscoverer) {
+	hosts, err := discoverer.DiscoverContext(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Warn("Discovery failed", "method", method, "error", err)
+		}
+		return
+	}
+	s.hostManager.UpdateDiscoveredHosts(hosts)
+}
+
+// listenForAnnouncements applies SSDP alive and byebye announcements to the
+// host manager as they arrive, until ctx is cancelled
+func (s *Server) listenForAnnouncements(ctx context.Context) {
+	if err := s.discovery.Listen(ctx, s.handleAnnouncement); err != nil {
+		s.logger.Warn("SSDP announcements unavailable, relying on periodic discovery", "error", err)
+	}
+}
+
+// handleAnnouncement adds an announced host or removes a departing one
+func (s *Server) handleAnnouncement(announcement redfish.SSDPAnnouncement) {
+	if announcement.Alive {
+		s.hostManager.UpdateDiscoveredHosts([]redfish.DiscoveredHost{announcement.Host})
+		return
+	}
+	s.hostManager.RemoveDiscoveredHost(announcement.Host)
+}
@@ -0,0 +1,202 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/config"
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/redfish"
+)
+
+// newSSDPResponder answers M-SEARCH requests on a local UDP port like a
+// Redfish service and counts the searches it received
+func newSSDPResponder(t *testing.T) (*net.UDPAddr, *atomic.Int32) {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	var searches atomic.Int32
+	go func() {
+		buffer := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFromUDP(buffer)
+			if err != nil {
+				return
+			}
+			if !strings.HasPrefix(string(buffer[:n]), "M-SEARCH") {
+				continue
+			}
+			searches.Add(1)
+			response := "HTTP/1.1 200 OK\r\n" +
+				"CACHE-CONTROL: max-age=1800\r\n" +
+				"ST: urn:dmtf-org:service:redfish-rest:1\r\n" +
+				"USN: uuid:00000000-0000-0000-0000-000000000001::urn:dmtf-org:service:redfish-rest:1\r\n" +
+				"AL: https://127.0.0.1/redfish/v1/\r\n\r\n"
+			conn.WriteToUDP([]byte(response), addr)
+		}
+	}()
+
+	return conn.LocalAddr().(*net.UDPAddr), &searches
+}
+
+func TestRunDiscoveryUpdatesHosts(t *testing.T) {
+	t.Setenv("REDFISH_HOSTS", `[{"address": "192.0.2.10"}]`)
+	server := newTestServer(t, config.MCPTransportStdio)
+	server.config.Redfish.DiscoveryEnabled = true
+	server.config.Redfish.DiscoveryInterval = 1
+
+	responder, searches := newSSDPResponder(t)
+	server.discovery = redfish.NewSSDPDiscovery(200*time.Millisecond, server.logger)
+	server.discovery.SetTarget(responder)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		server.runDiscovery(ctx)
+		close(stopped)
+	}()
+
+	// The first search runs immediately and a second one after the interval
+	deadline := time.Now().Add(5 * time.Second)
+	for searches.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected periodic searches, got %d", searches.Load())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if _, found := server.hostManager.GetHostByAddress("127.0.0.1"); !found {
+		t.Errorf("Discovered host missing, hosts: %v", server.hostManager.GetAddresses())
+	}
+	if _, found := server.hostManager.GetHostByAddress("192.0.2.10"); !found {
+		t.Errorf("Static host missing, hosts: %v", server.hostManager.GetAddresses())
+	}
+	if got := len(server.hostManager.GetAddresses()); got != 2 {
+		t.Errorf("Expected 2 hosts, got %d", got)
+	}
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Discovery loop did not stop after cancellation")
+	}
+}
+
+func TestSlowSweepDoesNotDelaySSDP(t *testing.T) {
+	server := newTestServer(t, config.MCPTransportStdio)
+	server.config.Redfish.DiscoveryInterval = 1
+
+	responder, searches := newSSDPResponder(t)
+	server.discovery = redfish.NewSSDPDiscovery(200*time.Millisecond, server.logger)
+	server.discovery.SetTarget(responder)
+
+	// A BMC that accepts connections but never answers holds the sweep for
+	// the whole probe timeout
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	server.sweep, err = redfish.NewSweepDiscovery(redfish.SweepConfig{
+		Ranges:  []string{"127.0.0.1/32"},
+		Port:    listener.Addr().(*net.TCPAddr).Port,
+		Timeout: time.Minute,
+	}, server.logger)
+	if err != nil {
+		t.Fatalf("NewSweepDiscovery failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		server.runDiscovery(ctx)
+		close(stopped)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for searches.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected SSDP searches to continue during the sweep, got %d", searches.Load())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Discovery loop did not stop after cancellation")
+	}
+}
+
+func TestDiscoverContextCancelled(t *testing.T) {
+	responder, _ := newSSDPResponder(t)
+	discovery := redfish.NewSSDPDiscovery(time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	discovery.SetTarget(responder)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := discovery.DiscoverContext(ctx); err == nil {
+		t.Fatal("Expected an error from a cancelled search")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Cancelled search took %s", elapsed)
+	}
+}
+
+func TestDiscoveryTimeout(t *testing.T) {
+	if got := discoveryTimeout(30 * time.Second); got != maxDiscoveryTimeout {
+		t.Errorf("Expected %s, got %s", maxDiscoveryTimeout, got)
+	}
+	if got := discoveryTimeout(time.Second); got != 500*time.Millisecond {
+		t.Errorf("Expected 500ms, got %s", got)
+	}
+}
+
+func TestHandleAnnouncement(t *testing.T) {
+	t.Setenv("REDFISH_HOSTS", `[{"address": "192.0.2.10"}]`)
+	server := newTestServer(t, config.MCPTransportStdio)
+
+	host := redfish.DiscoveredHost{
+		Address:   "192.0.2.20",
+		Port:      8443,
+		USN:       "uuid:a::urn:dmtf-org:service:redfish-rest:1",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	server.handleAnnouncement(redfish.SSDPAnnouncement{Alive: true, Host: host})
+
+	hostConfig, found := server.hostManager.GetHostByAddress("192.0.2.20")
+	if !found || hostConfig.Port != 8443 {
+		t.Fatalf("Expected announced host on port 8443, got %+v (found=%v)", hostConfig, found)
+	}
+
+	server.handleAnnouncement(redfish.SSDPAnnouncement{Host: redfish.DiscoveredHost{
+		Address: "192.0.2.20",
+		USN:     host.USN,
+	}})
+	if _, found := server.hostManager.GetHostByAddress("192.0.2.20"); found {
+		t.Error("Expected host to be removed after byebye")
+	}
+}
@@ -0,0 +1,172 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/auth"
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/config"
+)
+
+const (
+	// sseEndpoint is the path the SSE transport is served on
+	sseEndpoint = "/sse"
+
+	// shutdownTimeout bounds how long in-flight HTTP requests may take to
+	// finish once the server context is cancelled
+	shutdownTimeout = 10 * time.Second
+
+	// authReportInterval is how often rejected request totals are logged
+	// while requests are being rejected
+	authReportInterval = time.Minute
+)
+
+// startHTTP starts the server with an HTTP-based transport
+func (s *Server) startHTTP(ctx context.Context) error {
+	addr := net.JoinHostPort(s.config.MCP.Host, strconv.Itoa(s.config.MCP.Port))
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	return s.serveHTTP(ctx, listener)
+}
+
+// serveHTTP serves the configured HTTP transport on the listener until ctx is
+// cancelled, then shuts the HTTP server down gracefully
+func (s *Server) serveHTTP(ctx context.Context, listener net.Listener) error {
+	httpServer := &http.Server{
+		Handler:           s.httpHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		// Long-lived SSE streams are tied to ctx so that they end on shutdown
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	if s.authenticator != nil {
+		go s.authenticator.ReportRejections(ctx, authReportInterval)
+	}
+
+	errCh := make(chan error, 1)
+	if s.certReloader != nil {
+		httpServer.TLSConfig = s.certReloader.tlsConfig()
+		go s.certReloader.watch(ctx)
+		go func() {
+			errCh <- httpServer.ServeTLS(listener, "", "")
+		}()
+	} else {
+		go func() {
+			errCh <- httpServer.Serve(listener)
+		}()
+	}
+
+	s.logger.Info("MCP HTTP server listening",
+		"address", listener.Addr().String(),
+		"transport", s.config.MCP.Transport,
+		"path", s.endpointPath(),
+		"tls", s.certReloader != nil)
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("HTTP server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	s.logger.Info("Shutting down MCP HTTP server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		httpServer.Close()
+		return fmt.Errorf("failed to shut down HTTP server: %w", err)
+	}
+
+	if s.authenticator != nil {
+		s.logger.Info("Authentication summary", "rejected", s.authenticator.Rejections())
+	}
+
+	return nil
+}
+
+// httpHandler builds the HTTP handler for the configured transport
+func (s *Server) httpHandler() http.Handler {
+	getServer := func(*http.Request) *mcp.Server {
+		return s.mcpServer
+	}
+
+	var handler http.Handler
+	switch s.config.MCP.Transport {
+	case config.MCPTransportSSE:
+		handler = mcp.NewSSEHandler(getServer, nil)
+	case config.MCPTransportStreamableHTTP:
+		// The handler gives each stateful session its own in-memory event
+		// store, which replays a dropped stream from Last-Event-ID. This SDK
+		// version does not expose the store in StreamableHTTPOptions.
+		handler = mcp.NewStreamableHTTPHandler(getServer, &mcp.StreamableHTTPOptions{
+			Stateless: s.config.MCP.Stateless,
+		})
+	default:
+		handler = http.NotFoundHandler()
+	}
+
+	if s.authenticator != nil {
+		handler = s.authenticator.Middleware(handler)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(s.endpointPath(), handler)
+	return mux
+}
+
+// endpointPath returns the path the configured HTTP transport is served on
+func (s *Server) endpointPath() string {
+	if s.config.MCP.Transport == config.MCPTransportSSE {
+		return sseEndpoint
+	}
+	return s.config.MCP.Path
+}
+
+// newAuthenticator loads the configured credentials for the HTTP transports.
+// It returns nil when authentication is disabled.
+func newAuthenticator(cfg *config.MCPConfig, logger *slog.Logger) (*auth.Authenticator, error) {
+	if !cfg.AuthEnabled() {
+		logger.Warn("MCP HTTP transport is running without authentication")
+		return nil, nil
+	}
+
+	var apiKeys *auth.APIKeyVerifier
+	if cfg.AuthAPIKeysFile != "" {
+		keys, err := auth.LoadAPIKeys(cfg.AuthAPIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = keys
+	}
+
+	var jwtVerifier *auth.JWTVerifier
+	if cfg.AuthJWKSFile != "" {
+		verifier, err := auth.LoadJWKS(cfg.AuthJWKSFile, cfg.AuthJWTIssuer, cfg.AuthJWTAudience)
+		if err != nil {
+			return nil, err
+		}
+		jwtVerifier = verifier
+	}
+
+	logger.Info("MCP HTTP authentication enabled",
+		"api_keys", apiKeys != nil,
+		"jwt", jwtVerifier != nil)
+
+	return auth.NewAuthenticator(apiKeys, jwtVerifier, logger)
+}
@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/redfish"
+)
+
+const (
+	// defaultSessionIdleTimeout applies when no idle timeout is configured
+	defaultSessionIdleTimeout = 5 * time.Minute
+	// defaultLoginTimeout bounds a pooled login when the host has no retry
+	// deadline
+	defaultLoginTimeout = 2 * time.Minute
+)
+
+// clientPool keeps one authenticated Redfish client per host so that tool
+// calls reuse BMC sessions instead of creating a new one each time
+type clientPool struct {
+	idleTimeout time.Duration
+	logger      *slog.Logger
+
+	mu      sync.Mutex
+	entries map[string]*poolEntry
+}
+
+// poolEntry is a pooled client and its usage state, guarded by clientPool.mu
+type poolEntry struct {
+	config redfish.ClientConfig
+	client *redfish.Client
+	err    error
+	// ready is closed once the client has logged in (or failed to)
+	ready chan struct{}
+
+	inUse    int
+	lastUsed time.Time
+	// retired entries are no longer in the map and are closed once unused
+	retired bool
+}
+
+// newClientPool creates a client pool
+func newClientPool(idleTimeout time.Duration, logger *slog.Logger) *clientPool {
+	if idleTimeout <= 0 {
+		idleTimeout = defaultSessionIdleTimeout
+	}
+	return &clientPool{
+		idleTimeout: idleTimeout,
+		logger:      logger,
+		entries:     make(map[string]*poolEntry),
+	}
+}
+
+// acquire returns a logged-in client for the host described by config. The
+// returned release function must be called once the caller is done with it.
+func (p *clientPool) acquire(ctx context.Context, config *redfish.ClientConfig) (*redfish.Client, func(), error) {
+	key := net.JoinHostPort(config.Address, strconv.Itoa(config.Port))
+
+	p.mu.Lock()
+	entry := p.entries[key]
+	if entry != nil && entry.config != *config {
+		// Host settings changed; stop handing out the old session
+		if stale := p.retireLocked(key, entry); stale != nil {
+			defer stale.Close()
+		}
+		entry = nil
+	}
+
+	if entry == nil {
+		entry = &poolEntry{
+			config: *config,
+			ready:  make(chan struct{}),
+		}
+		p.entries[key] = entry
+		entry.inUse++
+		p.mu.Unlock()
+
+		// The login is shared with every caller waiting on the entry, so it
+		// must not end when the caller that started it gives up
+		go p.connect(context.WithoutCancel(ctx), key, entry)
+	} else {
+		entry.inUse++
+		p.mu.Unlock()
+	}
+
+	release := func() {
+		p.release(entry)
+	}
+
+	select {
+	case <-entry.ready:
+	case <-ctx.Done():
+		release()
+		return nil, nil, ctx.Err()
+	}
+
+	if entry.err != nil {
+		release()
+		return nil, nil, entry.err
+	}
+
+	return entry.client, release, nil
+}
+
+// connect creates the client and logs in, then wakes up waiting callers. The
+// login is bounded by the host's retry deadline, or defaultLoginTimeout.
+func (p *clientPool) connect(ctx context.Context, key string, entry *poolEntry) {
+	timeout := entry.config.RetryDeadline
+	if timeout <= 0 {
+		timeout = defaultLoginTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client, err := redfish.NewClient(&entry.config, p.logger)
+	if err != nil {
+		err = fmt.Errorf("failed to create Redfish client: %w", err)
+	} else if loginErr := client.LoginContext(ctx); loginErr != nil {
+		client.Close()
+		client = nil
+		err = fmt.Errorf("failed to login to Redfish server: %w", loginErr)
+	}
+
+	p.mu.Lock()
+	entry.client = client
+	entry.err = err
+	entry.lastUsed = time.Now()
+	if err != nil && p.entries[key] == entry {
+		// Don't cache failures; the next call retries the login
+		delete(p.entries, key)
+	}
+	// Every caller may have given up and the entry been retired meanwhile
+	closeNow := entry.retired && entry.inUse == 0 && client != nil
+	p.mu.Unlock()
+
+	if err == nil {
+		p.logger.Info("Created pooled Redfish session", "host", key)
+	}
+	close(entry.ready)
+	if closeNow {
+		client.Close()
+	}
+}
+
+// release marks one use of the entry as finished
+func (p *clientPool) release(entry *poolEntry) {
+	p.mu.Lock()
+	entry.inUse--
+	entry.lastUsed = time.Now()
+	closeNow := entry.retired && entry.inUse == 0 && entry.client != nil
+	p.mu.Unlock()
+
+	if closeNow {
+		entry.client.Close()
+	}
+}
+
+// retireLocked removes an entry from the pool. It returns the client if it
+// is idle and should be closed by the caller after releasing p.mu; busy
+// clients are closed by the last release. Callers must hold p.mu.
+func (p *clientPool) retireLocked(key string, entry *poolEntry) *redfish.Client {
+	delete(p.entries, key)
+	entry.retired = true
+	if entry.inUse == 0 && entry.client != nil {
+		return entry.client
+	}
+	return nil
+}
+
+// evictIdle closes clients that have not been used for the idle timeout
+func (p *clientPool) evictIdle(now time.Time) {
+	var evicted []*redfish.Client
+
+	p.mu.Lock()
+	for key, entry := range p.entries {
+		if entry.inUse == 0 && entry.client != nil && now.Sub(entry.lastUsed) >= p.idleTimeout {
+			evicted = append(evicted, p.retireLocked(key, entry))
+			p.logger.Info("Evicting idle Redfish session", "host", key)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, client := range evicted {
+		client.Close()
+	}
+}
+
+// run evicts idle clients until ctx is cancelled
+func (p *clientPool) run(ctx context.Context) {
+	interval := p.idleTimeout / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			p.evictIdle(now)
+		}
+	}
+}
+
+// closeAll retires every client, closing those not currently in use
+func (p *clientPool) closeAll() {
+	var idle []*redfish.Client
+
+	p.mu.Lock()
+	for key, entry := range p.entries {
+		if client := p.retireLocked(key, entry); client != nil {
+			idle = append(idle, client)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, client := range idle {
+		client.Close()
+	}
+}
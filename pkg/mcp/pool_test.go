@@ -0,0 +1,189 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/redfish"
+)
+
+// newFakeBMC starts a TLS server that hands out session tokens and returns a
+// client config for it along with the number of sessions created
+func newFakeBMC(t *testing.T) (*redfish.ClientConfig, *atomic.Int32) {
+	t.Helper()
+
+	var logins atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /redfish/v1/SessionService/Sessions", func(w http.ResponseWriter, r *http.Request) {
+		n := logins.Add(1)
+		w.Header().Set("X-Auth-Token", "token-"+strconv.Itoa(int(n)))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("GET /redfish/v1/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Id": "RootService"}`))
+	})
+
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	config := redfish.DefaultClientConfig()
+	config.Address = host
+	config.Port = port
+	config.Username = "admin"
+	config.Password = "password"
+	config.InsecureSkipVerify = true
+	return config, &logins
+}
+
+func TestClientPoolReusesSessions(t *testing.T) {
+	config, logins := newFakeBMC(t)
+	pool := newClientPool(time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	defer pool.closeAll()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client, release, err := pool.acquire(context.Background(), config)
+			if err != nil {
+				t.Errorf("acquire failed: %v", err)
+				return
+			}
+			defer release()
+			if _, err := client.Get("/redfish/v1/"); err != nil {
+				t.Errorf("Get failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := logins.Load(); n != 1 {
+		t.Errorf("Expected 1 session for concurrent calls, got %d", n)
+	}
+
+	// Idle sessions are evicted and a new one is created on next use
+	pool.evictIdle(time.Now().Add(time.Minute))
+	_, release, err := pool.acquire(context.Background(), config)
+	if err != nil {
+		t.Fatalf("acquire after eviction failed: %v", err)
+	}
+	release()
+	if n := logins.Load(); n != 2 {
+		t.Errorf("Expected a new session after eviction, got %d sessions", n)
+	}
+
+	// Sessions in use are never evicted
+	_, release, _ = pool.acquire(context.Background(), config)
+	pool.evictIdle(time.Now().Add(time.Hour))
+	release()
+	_, release, _ = pool.acquire(context.Background(), config)
+	release()
+	if n := logins.Load(); n != 2 {
+		t.Errorf("Expected busy session to survive eviction, got %d sessions", n)
+	}
+
+	// Changed host settings replace the pooled session
+	changed := *config
+	changed.Username = "operator"
+	_, release, _ = pool.acquire(context.Background(), &changed)
+	release()
+	if n := logins.Load(); n != 3 {
+		t.Errorf("Expected a new session after config change, got %d sessions", n)
+	}
+}
+
+func TestClientPoolDoesNotCacheLoginFailures(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	config := redfish.DefaultClientConfig()
+	config.Address = host
+	config.Port, _ = strconv.Atoi(portStr)
+	config.InsecureSkipVerify = true
+
+	pool := newClientPool(time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for i := 0; i < 2; i++ {
+		if _, _, err := pool.acquire(context.Background(), config); err == nil {
+			t.Fatal("Expected login failure")
+		}
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("Expected each call to retry the login, got %d attempts", n)
+	}
+}
+
+func TestClientPoolLoginOutlivesCreatingCaller(t *testing.T) {
+	unblock := make(chan struct{})
+	var logins atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /redfish/v1/SessionService/Sessions", func(w http.ResponseWriter, r *http.Request) {
+		logins.Add(1)
+		<-unblock
+		w.Header().Set("X-Auth-Token", "token")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{}`))
+	})
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+	defer close(unblock)
+
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	config := redfish.DefaultClientConfig()
+	config.Address = host
+	config.Port, _ = strconv.Atoi(portStr)
+	config.Username = "admin"
+	config.Password = "password"
+	config.InsecureSkipVerify = true
+
+	pool := newClientPool(time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	defer pool.closeAll()
+
+	// The caller that starts the login gives up before it completes
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, _, err := pool.acquire(ctx, config); err == nil {
+		t.Fatal("Expected the first caller to time out")
+	}
+
+	// A caller waiting on the same login still gets the session
+	result := make(chan error, 1)
+	go func() {
+		_, release, err := pool.acquire(context.Background(), config)
+		if err == nil {
+			release()
+		}
+		result <- err
+	}()
+	unblock <- struct{}{}
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("Expected the waiting caller to get a session, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Waiting caller did not get a session")
+	}
+	if n := logins.Load(); n != 1 {
+		t.Errorf("Expected 1 login, got %d", n)
+	}
+}
@@ -2,11 +2,19 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/auth"
 	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/common"
 	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/config"
 	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/redfish"
@@ -14,10 +22,22 @@ import (
 
 // Server wraps the MCP server with Redfish-specific functionality
 type Server struct {
-	mcpServer   *mcp.Server
-	config      *config.Config
-	hostManager *common.HostManager
-	logger      *slog.Logger
+	mcpServer     *mcp.Server
+	config        *config.Config
+	hostManager   *common.HostManager
+	clientPool    *clientPool
+	breaker       *circuitBreaker
+	pinStore      *redfish.PinStore
+	discovery     *redfish.SSDPDiscovery
+	sweep         *redfish.SweepDiscovery
+	authenticator *auth.Authenticator
+	certReloader  *certReloader
+	logger        *slog.Logger
+
+	// limiters holds the shared request limiter of each host by
+	// "address:port"
+	limitersMu sync.Mutex
+	limiters   map[string]*redfish.Limiter
 }
 
 // NewServer creates a new Redfish MCP server
@@ -40,13 +60,62 @@ func NewServer(cfg *config.Config, logger *slog.Logger) (*Server, error) {
 	// Create host manager
 	hostManager := common.NewHostManager(logger)
 
+	// Load trust-on-first-use certificate pins
+	pinStore, err := redfish.NewPinStore(cfg.Redfish.TLSPinStoreFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pin store: %w", err)
+	}
+
+	breakerCooldown := time.Duration(cfg.Redfish.CircuitBreakerCooldown) * time.Second
+	breaker := newCircuitBreaker(cfg.Redfish.CircuitBreakerThreshold, breakerCooldown, logger)
+
+	discoveryInterval := time.Duration(cfg.Redfish.DiscoveryInterval) * time.Second
+	discovery := redfish.NewSSDPDiscovery(discoveryTimeout(discoveryInterval), logger)
+	discovery.SetInterfaces(cfg.Redfish.DiscoveryInterfaces)
+	discovery.SetIPv6(cfg.Redfish.DiscoveryIPv6)
+
+	var sweep *redfish.SweepDiscovery
+	if len(cfg.Redfish.DiscoverySweepRanges) > 0 {
+		sweep, err = redfish.NewSweepDiscovery(redfish.SweepConfig{
+			Ranges:      cfg.Redfish.DiscoverySweepRanges,
+			Port:        cfg.Redfish.Port,
+			Concurrency: cfg.Redfish.DiscoverySweepConcurrency,
+			Timeout:     time.Duration(cfg.Redfish.DiscoverySweepTimeout) * time.Second,
+			Lifetime:    sweepLifetime(discoveryInterval),
+		}, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure sweep discovery: %w", err)
+		}
+	}
+
 	server := &Server{
 		mcpServer:   mcpServer,
 		config:      cfg,
 		hostManager: hostManager,
+		clientPool:  newClientPool(time.Duration(cfg.Redfish.SessionIdleTimeout)*time.Second, logger),
+		breaker:     breaker,
+		pinStore:    pinStore,
+		discovery:   discovery,
+		sweep:       sweep,
+		limiters:    make(map[string]*redfish.Limiter),
 		logger:      logger,
 	}
 
+	// Set up authentication and TLS for network transports
+	if cfg.MCP.IsHTTP() {
+		authenticator, err := newAuthenticator(cfg.MCP, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure authentication: %w", err)
+		}
+		server.authenticator = authenticator
+
+		reloader, err := newCertReloader(cfg.MCP, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure TLS: %w", err)
+		}
+		server.certReloader = reloader
+	}
+
 	// Register tools
 	if err := server.registerTools(); err != nil {
 		return nil, fmt.Errorf("failed to register tools: %w", err)
@@ -56,15 +125,66 @@ func NewServer(cfg *config.Config, logger *slog.Logger) (*Server, error) {
 	return server, nil
 }
 
+// certificateFetchTimeout bounds the TLS handshake used to read a host's certificate
+const certificateFetchTimeout = 10 * time.Second
+
 // GetResourceInput represents input for the get_resource_data tool
 type GetResourceInput struct {
 	URL string `json:"url" jsonschema:"Redfish resource URL"`
+	// FollowNextLinks fetches every page of a paged collection
+	FollowNextLinks bool `json:"follow_next_links,omitempty" jsonschema:"Follow Members@odata.nextLink and return the Members of all pages merged"`
+	MaxMembers      int  `json:"max_members,omitempty" jsonschema:"Maximum number of collection members to gather when following nextLinks (default 1000)"`
+
+	// OData query options; $select and $expand are emulated when the server
+	// does not support them
+	Select       []string `json:"select,omitempty" jsonschema:"Properties to return ($select); nested properties as Status/Health"`
+	Expand       string   `json:"expand,omitempty" jsonschema:"Expand hyperlinks ($expand): * for all, . for subordinate resources, ~ for resources under Links"`
+	ExpandLevels int      `json:"expand_levels,omitempty" jsonschema:"Number of levels to expand (default 1)"`
+	Filter       string   `json:"filter,omitempty" jsonschema:"Collection member filter ($filter), e.g. Severity eq 'Critical'"`
+	Top          int      `json:"top,omitempty" jsonschema:"Return at most this many collection members ($top)"`
+	Skip         int      `json:"skip,omitempty" jsonschema:"Skip this many collection members ($skip)"`
 }
 
 // GetResourceOutput represents output for the get_resource_data tool
 type GetResourceOutput struct {
-	Headers map[string][]string `json:"headers"`
-	Data    interface{}         `json:"data"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Data    interface{}         `json:"data,omitempty"`
+	// Truncated is set when following nextLinks stopped at max_members
+	Truncated bool `json:"truncated,omitempty"`
+	// Emulated lists query options applied by this server because the BMC
+	// does not support them
+	Emulated []string `json:"emulated,omitempty"`
+	// Error is set when the BMC rejected the request
+	Error *ToolError `json:"error,omitempty"`
+}
+
+// ToolError describes a failed Redfish request so agents can act on the
+// service's resolution text
+type ToolError struct {
+	Message string `json:"message"`
+	// Status is the HTTP status code, zero for network errors
+	Status       int                   `json:"status,omitempty"`
+	Class        redfish.ErrorClass    `json:"class"`
+	Code         string                `json:"code,omitempty"`
+	ExtendedInfo []redfish.MessageInfo `json:"extended_info,omitempty"`
+}
+
+// redfishErrorResult converts a Redfish error response into a tool error
+// result carrying the structured details. ok is false for other errors.
+func redfishErrorResult(err error) (*mcp.CallToolResult, *ToolError, bool) {
+	var redfishErr *redfish.RedfishError
+	if !errors.As(err, &redfishErr) || redfishErr.Code == 0 {
+		return nil, nil, false
+	}
+
+	toolErr := &ToolError{
+		Message:      err.Error(),
+		Status:       redfishErr.Code,
+		Class:        redfish.Classify(err),
+		Code:         redfishErr.ErrorCode,
+		ExtendedInfo: redfishErr.ExtendedInfo,
+	}
+	return &mcp.CallToolResult{IsError: true}, toolErr, true
 }
 
 // registerTools registers the MCP tools
@@ -81,6 +201,18 @@ func (s *Server) registerTools() error {
 		Description: "Fetch data from a specific Redfish resource",
 	}, s.handleGetResourceData)
 
+	// Register get_certificate_fingerprint tool
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "get_certificate_fingerprint",
+		Description: "Report the TLS certificate fingerprints a Redfish server currently presents, for enrolling certificate pins",
+	}, s.handleGetCertificateFingerprint)
+
+	// Register resolve_message tool
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "resolve_message",
+		Description: "Expand a Redfish MessageId, as found in error extended info and log entries, into its message text, severity and resolution",
+	}, s.handleResolveMessage)
+
 	s.logger.Info("MCP tools registered successfully")
 	return nil
 }
@@ -88,6 +220,9 @@ func (s *Server) registerTools() error {
 // ListServersOutput represents the output for the list_servers tool
 type ListServersOutput struct {
 	Servers []string `json:"servers"`
+	// Breakers holds the circuit breaker state of each server by
+	// "address:port"
+	Breakers map[string]BreakerStatus `json:"breakers"`
 }
 
 // handleListServers handles the list_servers tool
@@ -96,7 +231,17 @@ func (s *Server) handleListServers(ctx context.Context, req *mcp.CallToolRequest
 
 	addresses := s.hostManager.GetAddresses()
 
-	return nil, ListServersOutput{Servers: addresses}, nil
+	breakers := make(map[string]BreakerStatus, len(addresses))
+	for _, address := range addresses {
+		hostConfig, found := s.hostManager.GetHostByAddress(address)
+		if !found {
+			continue
+		}
+		key := s.hostKey(hostConfig)
+		breakers[key] = s.breaker.status(key)
+	}
+
+	return nil, ListServersOutput{Servers: addresses, Breakers: breakers}, nil
 }
 
 // handleGetResourceData handles the get_resource_data tool
@@ -115,28 +260,159 @@ func (s *Server) handleGetResourceData(ctx context.Context, req *mcp.CallToolReq
 		return nil, GetResourceOutput{}, fmt.Errorf("server %s not found in configuration", serverAddr)
 	}
 
-	// Create Redfish client
-	clientConfig := s.createClientConfig(hostConfig)
-	client := redfish.NewClient(clientConfig, s.logger)
-
-	// Login and fetch data
-	if err := client.Login(); err != nil {
-		return nil, GetResourceOutput{}, fmt.Errorf("failed to login to Redfish server: %w", err)
+	// Get a logged-in client, reusing the host's pooled session
+	client, done, err := s.acquireClient(ctx, hostConfig)
+	if err != nil {
+		return nil, GetResourceOutput{}, err
 	}
-	defer client.Close()
 
 	// Get resource data with headers
-	response, err := client.GetWithHeaders(resourcePath)
+	result, err := client.QueryContext(ctx, resourcePath, redfish.Query{
+		Select:          input.Select,
+		Expand:          input.Expand,
+		ExpandLevels:    input.ExpandLevels,
+		Filter:          input.Filter,
+		Top:             input.Top,
+		Skip:            input.Skip,
+		FollowNextLinks: input.FollowNextLinks,
+		MaxMembers:      input.MaxMembers,
+	})
+	defer done(err)
 	if err != nil {
-		return nil, GetResourceOutput{}, fmt.Errorf("failed to get resource data: %w", err)
+		if redfish.Classify(err) == redfish.ErrorClassThrottled {
+			err = fmt.Errorf("server %s is busy, try again later: %w", serverAddr, err)
+		} else {
+			err = fmt.Errorf("failed to get resource data: %w", err)
+		}
+		if result, toolErr, ok := redfishErrorResult(err); ok {
+			toolErr.ExtendedInfo = client.ExpandMessages(ctx, toolErr.ExtendedInfo)
+			return result, GetResourceOutput{Error: toolErr}, nil
+		}
+		return nil, GetResourceOutput{}, err
 	}
 
 	return nil, GetResourceOutput{
-		Headers: response.Headers,
-		Data:    response.Data,
+		Headers:   result.Response.Headers,
+		Data:      result.Response.Data,
+		Truncated: result.Truncated,
+		Emulated:  result.Emulated,
 	}, nil
 }
 
+// GetCertificateInput represents input for the get_certificate_fingerprint tool
+type GetCertificateInput struct {
+	Server string `json:"server" jsonschema:"Server address as returned by list_servers"`
+}
+
+// GetCertificateOutput represents output for the get_certificate_fingerprint tool
+type GetCertificateOutput struct {
+	Certificate *redfish.CertificateInfo `json:"certificate"`
+	// PinSource is "configured", "trust_on_first_use" or empty when unpinned
+	PinSource  string `json:"pin_source,omitempty"`
+	Pin        string `json:"pin,omitempty"`
+	PinMatches *bool  `json:"pin_matches,omitempty"`
+}
+
+// handleGetCertificateFingerprint handles the get_certificate_fingerprint tool
+func (s *Server) handleGetCertificateFingerprint(ctx context.Context, req *mcp.CallToolRequest, input GetCertificateInput) (*mcp.CallToolResult, GetCertificateOutput, error) {
+	s.logger.Info("Handling get_certificate_fingerprint request", "server", input.Server)
+
+	hostConfig, found := s.hostManager.GetHostByAddress(input.Server)
+	if !found {
+		return nil, GetCertificateOutput{}, fmt.Errorf("server %s not found in configuration", input.Server)
+	}
+
+	clientConfig := s.createClientConfig(hostConfig)
+	info, err := redfish.FetchCertificate(ctx, clientConfig.Address, clientConfig.Port, clientConfig.TLSServerName, certificateFetchTimeout)
+	if err != nil {
+		return nil, GetCertificateOutput{}, fmt.Errorf("failed to fetch certificate: %w", err)
+	}
+
+	output := GetCertificateOutput{Certificate: info}
+	switch {
+	case hostConfig.TLSPinSHA256 != "":
+		output.PinSource = "configured"
+		output.Pin = hostConfig.TLSPinSHA256
+	case hostConfig.TLSTrustOnFirstUse:
+		if pin, ok := s.pinStore.Get(clientConfig.Address, clientConfig.Port); ok {
+			output.PinSource = "trust_on_first_use"
+			output.Pin = pin
+		}
+	}
+
+	if output.Pin != "" {
+		pin, err := redfish.ParsePin(output.Pin)
+		if err != nil {
+			return nil, GetCertificateOutput{}, err
+		}
+		matches := info.Matches(pin)
+		output.PinMatches = &matches
+	}
+
+	return nil, output, nil
+}
+
+// ResolveMessageInput represents input for the resolve_message tool
+type ResolveMessageInput struct {
+	Server      string   `json:"server" jsonschema:"Server address as returned by list_servers"`
+	MessageID   string   `json:"message_id" jsonschema:"Redfish MessageId, e.g. Base.1.8.PropertyValueNotInList"`
+	MessageArgs []string `json:"message_args,omitempty" jsonschema:"Arguments substituted for %1, %2, ... in the message"`
+}
+
+// ResolveMessageOutput represents output for the resolve_message tool
+type ResolveMessageOutput struct {
+	MessageID   string `json:"message_id"`
+	Message     string `json:"message"`
+	Severity    string `json:"severity,omitempty"`
+	Resolution  string `json:"resolution,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// handleResolveMessage handles the resolve_message tool
+func (s *Server) handleResolveMessage(ctx context.Context, req *mcp.CallToolRequest, input ResolveMessageInput) (*mcp.CallToolResult, ResolveMessageOutput, error) {
+	s.logger.Info("Handling resolve_message request", "server", input.Server, "message_id", input.MessageID)
+
+	hostConfig, found := s.hostManager.GetHostByAddress(input.Server)
+	if !found {
+		return nil, ResolveMessageOutput{}, fmt.Errorf("server %s not found in configuration", input.Server)
+	}
+
+	var message redfish.RegistryMessage
+	var ok bool
+	client, done, err := s.acquireClient(ctx, hostConfig)
+	if err != nil {
+		// The bundled registries may still know the message
+		s.logger.Warn("Cannot reach server for its message registries, using bundled registries",
+			"server", input.Server,
+			"error", err)
+		message, ok = redfish.BundledRegistries().Lookup(input.MessageID)
+	} else {
+		var lookupErr error
+		message, ok, lookupErr = client.LookupMessage(ctx, input.MessageID)
+		if lookupErr != nil {
+			s.logger.Warn("Failed to fetch message registries, using bundled registries",
+				"server", input.Server,
+				"error", lookupErr)
+		}
+		done(lookupErr)
+	}
+	if !ok {
+		return nil, ResolveMessageOutput{}, fmt.Errorf("message %s not found in the registries of %s or the bundled DMTF registries", input.MessageID, input.Server)
+	}
+
+	output := ResolveMessageOutput{
+		MessageID:   input.MessageID,
+		Message:     message.Format(input.MessageArgs),
+		Severity:    message.MessageSeverity,
+		Resolution:  message.Resolution,
+		Description: message.Description,
+	}
+	if output.Severity == "" {
+		output.Severity = message.Severity
+	}
+	return nil, output, nil
+}
+
 // parseRedfishURL parses a Redfish URL to extract server address and resource path
 func (s *Server) parseRedfishURL(url string) (string, string, error) {
 	// This is a simplified parser - in production, use proper URL parsing
@@ -165,6 +441,19 @@ func (s *Server) parseRedfishURL(url string) (string, string, error) {
 	serverAddr := withoutScheme[:hostEnd]
 	resourcePath := withoutScheme[hostEnd:]
 
+	// Drop an explicit port, which comes from the host configuration, and
+	// the brackets of an IPv6 address; a link-local zone is encoded as %25
+	if host, _, err := net.SplitHostPort(serverAddr); err == nil {
+		serverAddr = host
+	} else {
+		serverAddr = strings.TrimSuffix(strings.TrimPrefix(serverAddr, "["), "]")
+	}
+	unescaped, err := neturl.PathUnescape(serverAddr)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid server address %s: %w", serverAddr, err)
+	}
+	serverAddr = unescaped
+
 	// Basic validation
 	if serverAddr == "" {
 		return "", "", fmt.Errorf("empty server address")
@@ -177,6 +466,71 @@ func (s *Server) parseRedfishURL(url string) (string, string, error) {
 	return serverAddr, resourcePath, nil
 }
 
+// acquireClient returns a pooled client for a host unless its circuit
+// breaker is open. done must be called with the outcome of the call.
+func (s *Server) acquireClient(ctx context.Context, hostConfig config.HostConfig) (*redfish.Client, func(error), error) {
+	key := s.hostKey(hostConfig)
+	if err := s.breaker.allow(key); err != nil {
+		return nil, nil, err
+	}
+
+	client, release, err := s.clientPool.acquire(ctx, s.createClientConfig(hostConfig))
+	if err != nil {
+		s.breaker.record(key, err)
+		return nil, nil, err
+	}
+
+	return client, func(err error) {
+		s.breaker.record(key, err)
+		release()
+	}, nil
+}
+
+// hostKey identifies a Redfish service by address and port, like the
+// session pool, so that services sharing an address are tracked separately
+func (s *Server) hostKey(hostConfig config.HostConfig) string {
+	port := hostConfig.Port
+	if port == 0 {
+		port = s.config.Redfish.Port
+	}
+	return net.JoinHostPort(hostConfig.Address, strconv.Itoa(port))
+}
+
+const (
+	// defaultMaxInFlight applies when no per-host concurrency is configured
+	defaultMaxInFlight = 2
+	// defaultQueueTimeout applies when no queue wait timeout is configured
+	defaultQueueTimeout = 30 * time.Second
+)
+
+// limiterFor returns the request limiter shared by all clients of a host,
+// replacing it when the host's limits change
+func (s *Server) limiterFor(hostConfig config.HostConfig) *redfish.Limiter {
+	maxInFlight := s.config.Redfish.MaxInFlight
+	if hostConfig.MaxInFlight > 0 {
+		maxInFlight = hostConfig.MaxInFlight
+	}
+	if maxInFlight <= 0 {
+		maxInFlight = defaultMaxInFlight
+	}
+
+	queueTimeout := time.Duration(s.config.Redfish.QueueTimeout) * time.Second
+	if queueTimeout <= 0 {
+		queueTimeout = defaultQueueTimeout
+	}
+
+	s.limitersMu.Lock()
+	defer s.limitersMu.Unlock()
+
+	key := s.hostKey(hostConfig)
+	limiter := s.limiters[key]
+	if limiter == nil || limiter.MaxInFlight() != maxInFlight || limiter.QueueTimeout() != queueTimeout {
+		limiter = redfish.NewLimiter(maxInFlight, queueTimeout)
+		s.limiters[key] = limiter
+	}
+	return limiter
+}
+
 // createClientConfig creates a Redfish client config from host config
 func (s *Server) createClientConfig(hostConfig config.HostConfig) *redfish.ClientConfig {
 	config := redfish.DefaultClientConfig()
@@ -208,21 +562,89 @@ func (s *Server) createClientConfig(hostConfig config.HostConfig) *redfish.Clien
 		config.TLSServerCACert = s.config.Redfish.TLSServerCACert
 	}
 
+	config.TLSServerCAMerge = s.config.Redfish.TLSServerCAMerge
+	if hostConfig.TLSServerCAMerge != nil {
+		config.TLSServerCAMerge = *hostConfig.TLSServerCAMerge
+	}
+
+	config.TLSClientCert = hostConfig.TLSClientCert
+	config.TLSClientKey = hostConfig.TLSClientKey
+
+	config.TLSPinSHA256 = hostConfig.TLSPinSHA256
+	if hostConfig.TLSTrustOnFirstUse {
+		config.TLSPinStore = s.pinStore
+	}
+
 	config.InsecureSkipVerify = s.config.Redfish.InsecureSkipVerify
+	if hostConfig.InsecureSkipVerify != nil {
+		config.InsecureSkipVerify = *hostConfig.InsecureSkipVerify
+	}
+
+	config.TLSMinVersion = hostConfig.TLSMinVersion
+	if config.TLSMinVersion == "" {
+		config.TLSMinVersion = s.config.Redfish.TLSMinVersion
+	}
+
+	config.TLSServerName = hostConfig.TLSServerName
+
+	config.Limiter = s.limiterFor(hostConfig)
+
+	applyRetryConfig(config, &s.config.Redfish.Retry)
+	if hostConfig.Retry != nil {
+		applyRetryConfig(config, hostConfig.Retry)
+	}
 
 	return config
 }
 
+// applyRetryConfig copies the set fields of a retry configuration onto the
+// client config
+func applyRetryConfig(clientConfig *redfish.ClientConfig, retry *config.RetryConfig) {
+	if retry.MaxRetries != nil {
+		clientConfig.MaxRetries = *retry.MaxRetries
+	}
+	if retry.InitialDelay != nil {
+		clientConfig.InitialDelay = secondsToDuration(*retry.InitialDelay)
+	}
+	if retry.MaxDelay != nil {
+		clientConfig.MaxDelay = secondsToDuration(*retry.MaxDelay)
+	}
+	if retry.BackoffFactor != nil {
+		clientConfig.BackoffFactor = *retry.BackoffFactor
+	}
+	if retry.Jitter != "" {
+		clientConfig.Jitter = redfish.JitterMode(retry.Jitter)
+	}
+	if retry.Deadline != nil {
+		clientConfig.RetryDeadline = secondsToDuration(*retry.Deadline)
+	}
+}
+
+// secondsToDuration converts fractional seconds from the configuration
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
 // Start starts the MCP server with the specified transport
 func (s *Server) Start(ctx context.Context) error {
 	s.logger.Info("Starting Redfish MCP server",
 		"transport", s.config.MCP.Transport)
 
-	// For now, we'll implement stdio transport
-	// Other transports can be added later
+	// Log out of pooled BMC sessions when the server stops
+	go s.clientPool.run(ctx)
+	defer s.clientPool.closeAll()
+
+	// Keep the discovered hosts up to date while the server runs
+	if s.config.Redfish.DiscoveryEnabled {
+		go s.runDiscovery(ctx)
+		go s.listenForAnnouncements(ctx)
+	}
+
 	switch s.config.MCP.Transport {
 	case config.MCPTransportStdio:
 		return s.startStdio(ctx)
+	case config.MCPTransportSSE, config.MCPTransportStreamableHTTP:
+		return s.startHTTP(ctx)
 	default:
 		return fmt.Errorf("unsupported transport: %s", s.config.MCP.Transport)
 	}
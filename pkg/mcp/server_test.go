@@ -0,0 +1,488 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/config"
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/redfish"
+)
+
+func newTestServer(t *testing.T, transport config.MCPTransport) *Server {
+	t.Helper()
+
+	cfg := &config.Config{
+		Redfish: &config.RedfishConfig{
+			Port:              443,
+			AuthMethod:        "session",
+			DiscoveryInterval: 30,
+		},
+		MCP: &config.MCPConfig{
+			Transport: transport,
+			LogLevel:  "INFO",
+			Host:      "127.0.0.1",
+			Path:      "/mcp",
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server, err := NewServer(cfg, logger)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	return server
+}
+
+// startTestHTTP serves the server on a random local port and returns its
+// address along with a function that stops the server and waits for it
+func startTestHTTP(t *testing.T, server *Server) (string, func() error) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.serveHTTP(ctx, listener)
+	}()
+
+	stop := func() error {
+		cancel()
+		select {
+		case err := <-errCh:
+			return err
+		case <-time.After(shutdownTimeout + time.Second):
+			t.Fatal("Server did not shut down in time")
+			return nil
+		}
+	}
+
+	return listener.Addr().String(), stop
+}
+
+func TestSSETransport(t *testing.T) {
+	server := newTestServer(t, config.MCPTransportSSE)
+	addr, stop := startTestHTTP(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	session, err := client.Connect(ctx, &mcp.SSEClientTransport{
+		Endpoint:   "http://" + addr + sseEndpoint,
+		HTTPClient: &http.Client{},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect over SSE: %v", err)
+	}
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, tool := range tools.Tools {
+		names[tool.Name] = true
+	}
+	for _, want := range []string{"list_servers", "get_resource_data"} {
+		if !names[want] {
+			t.Errorf("Expected tool %s to be registered", want)
+		}
+	}
+
+	// Shutdown must not wait for the open SSE stream to be closed by the client
+	if err := stop(); err != nil {
+		t.Fatalf("Server shutdown failed: %v", err)
+	}
+	session.Close()
+}
+
+func TestStreamableHTTPTransport(t *testing.T) {
+	server := newTestServer(t, config.MCPTransportStreamableHTTP)
+	server.config.MCP.Path = "/custom/mcp"
+	addr, stop := startTestHTTP(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint: "http://" + addr + "/custom/mcp",
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect over streamable HTTP: %v", err)
+	}
+
+	if session.ID() == "" {
+		t.Error("Expected a stateful session with an Mcp-Session-Id")
+	}
+
+	if _, err := session.ListTools(ctx, nil); err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+
+	// The default path is not served when a custom one is configured
+	resp, err := http.Post("http://"+addr+"/mcp", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 on unconfigured path, got %d", resp.StatusCode)
+	}
+
+	if err := stop(); err != nil {
+		t.Fatalf("Server shutdown failed: %v", err)
+	}
+	session.Close()
+}
+
+// readSSEEvent reads the next event from an SSE stream and returns its ID and
+// data
+func readSSEEvent(t *testing.T, r *bufio.Reader) (string, string) {
+	t.Helper()
+
+	var id, data string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read SSE event: %v", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "" && data != "":
+			return id, data
+		case strings.HasPrefix(line, "id:"):
+			id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+	}
+}
+
+func TestStreamableHTTPResumesWithLastEventID(t *testing.T) {
+	server := newTestServer(t, config.MCPTransportStreamableHTTP)
+	addr, stop := startTestHTTP(t, server)
+	defer stop()
+	endpoint := "http://" + addr + "/mcp"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var sessionID string
+	send := func(ctx context.Context, method, lastEventID, body string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, method, endpoint, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Mcp-Protocol-Version", "2025-06-18")
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s failed: %v", method, err)
+		}
+		return resp
+	}
+
+	resp := send(ctx, http.MethodPost, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test-client","version":"0.0.1"}}}`)
+	sessionID = resp.Header.Get("Mcp-Session-Id")
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if sessionID == "" {
+		t.Fatal("Expected an Mcp-Session-Id from initialize")
+	}
+	resp = send(ctx, http.MethodPost, "", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	resp.Body.Close()
+
+	// Each removal is announced on the session's standalone stream
+	server.mcpServer.RemoveTools("list_servers")
+	server.mcpServer.RemoveTools("get_resource_data")
+
+	streamCtx, dropStream := context.WithCancel(ctx)
+	resp = send(streamCtx, http.MethodGet, "", "")
+	firstID, _ := readSSEEvent(t, bufio.NewReader(resp.Body))
+	dropStream()
+	resp.Body.Close()
+
+	// The dropped stream may still be held by the server briefly
+	for {
+		resp = send(ctx, http.MethodGet, firstID, "")
+		if resp.StatusCode != http.StatusConflict {
+			break
+		}
+		resp.Body.Close()
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected resumed stream to return 200, got %d", resp.StatusCode)
+	}
+
+	id, data := readSSEEvent(t, bufio.NewReader(resp.Body))
+	if id == firstID {
+		t.Errorf("Expected the event after %s to be replayed, got it again", firstID)
+	}
+	if !strings.Contains(data, "notifications/tools/list_changed") {
+		t.Errorf("Expected replayed tools/list_changed notification, got %s", data)
+	}
+}
+
+func TestCreateClientConfigTLSOverrides(t *testing.T) {
+	server := newTestServer(t, config.MCPTransportStdio)
+	server.config.Redfish.InsecureSkipVerify = true
+	server.config.Redfish.TLSMinVersion = "1.2"
+
+	inherited := server.createClientConfig(config.HostConfig{Address: "10.0.0.1"})
+	if !inherited.InsecureSkipVerify || inherited.TLSMinVersion != "1.2" {
+		t.Errorf("Expected global TLS settings, got skip=%v min=%s", inherited.InsecureSkipVerify, inherited.TLSMinVersion)
+	}
+
+	verify := false
+	overridden := server.createClientConfig(config.HostConfig{
+		Address:            "10.0.0.2",
+		InsecureSkipVerify: &verify,
+		TLSMinVersion:      "1.3",
+		TLSServerName:      "bmc2.example.com",
+	})
+	if overridden.InsecureSkipVerify {
+		t.Error("Expected per-host insecure_skip_verify=false to override global setting")
+	}
+	if overridden.TLSMinVersion != "1.3" {
+		t.Errorf("Expected min TLS version 1.3, got %s", overridden.TLSMinVersion)
+	}
+	if overridden.TLSServerName != "bmc2.example.com" {
+		t.Errorf("Expected server name bmc2.example.com, got %s", overridden.TLSServerName)
+	}
+}
+
+// connectTestClient connects an MCP client to the server over in-memory
+// transports, closing both sessions when the test ends
+func connectTestClient(t *testing.T, ctx context.Context, server *Server) *mcp.ClientSession {
+	t.Helper()
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.mcpServer.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Server connect failed: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Client connect failed: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+func TestGetResourceDataReportsExtendedInfo(t *testing.T) {
+	bmc := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {
+			"code": "Base.1.8.GeneralError",
+			"message": "A general error has occurred.",
+			"@Message.ExtendedInfo": [{
+				"MessageId": "Base.1.8.PropertyValueNotInList",
+				"Message": "The value Sideways for the property IndicatorLED is not in the list of acceptable values.",
+				"MessageArgs": ["Sideways", "IndicatorLED"],
+				"Severity": "Warning",
+				"Resolution": "Choose a value from the enumeration list and resubmit the request."
+			}]
+		}}`))
+	}))
+	defer bmc.Close()
+
+	_, port, _ := net.SplitHostPort(bmc.Listener.Addr().String())
+	t.Setenv("REDFISH_HOSTS", `[{"address": "127.0.0.1", "port": `+port+`, "auth_method": "basic"}]`)
+
+	server := newTestServer(t, config.MCPTransportStdio)
+	server.config.Redfish.InsecureSkipVerify = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session := connectTestClient(t, ctx, server)
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "get_resource_data",
+		Arguments: map[string]any{"url": "https://127.0.0.1/redfish/v1/Systems/1"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("Expected an error result")
+	}
+
+	var output GetResourceOutput
+	raw, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(raw, &output); err != nil {
+		t.Fatalf("Failed to decode structured content: %v", err)
+	}
+	if output.Error == nil {
+		t.Fatalf("Expected structured error, got: %s", raw)
+	}
+	if output.Error.Status != http.StatusBadRequest || output.Error.Class != redfish.ErrorClassClient {
+		t.Errorf("Expected 400 client error, got status=%d class=%s", output.Error.Status, output.Error.Class)
+	}
+	if len(output.Error.ExtendedInfo) != 1 {
+		t.Fatalf("Expected 1 extended info entry, got %d", len(output.Error.ExtendedInfo))
+	}
+	info := output.Error.ExtendedInfo[0]
+	if info.MessageID != "Base.1.8.PropertyValueNotInList" || info.Resolution == "" {
+		t.Errorf("Unexpected extended info: %+v", info)
+	}
+}
+
+func TestGetCertificateFingerprint(t *testing.T) {
+	bmc := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer bmc.Close()
+
+	_, port, _ := net.SplitHostPort(bmc.Listener.Addr().String())
+	t.Setenv("REDFISH_HOSTS", `[{"address": "127.0.0.1", "port": `+port+`, "auth_method": "basic", "tls_trust_on_first_use": true}]`)
+
+	server := newTestServer(t, config.MCPTransportStdio)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	session := connectTestClient(t, ctx, server)
+
+	getFingerprint := func() GetCertificateOutput {
+		t.Helper()
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "get_certificate_fingerprint",
+			Arguments: map[string]any{"server": "127.0.0.1"},
+		})
+		if err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("Unexpected error result: %+v", result.Content)
+		}
+		var output GetCertificateOutput
+		raw, _ := json.Marshal(result.StructuredContent)
+		if err := json.Unmarshal(raw, &output); err != nil {
+			t.Fatalf("Failed to decode structured content: %v", err)
+		}
+		return output
+	}
+
+	want := redfish.NewCertificateInfo(bmc.Certificate())
+	output := getFingerprint()
+	if output.Certificate == nil || output.Certificate.SPKISHA256 != want.SPKISHA256 {
+		t.Fatalf("Expected SPKI fingerprint %s, got %+v", want.SPKISHA256, output.Certificate)
+	}
+	if output.PinSource != "" || output.PinMatches != nil {
+		t.Errorf("Expected no pin before first contact, got %+v", output)
+	}
+
+	// A request records the pin on first use
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "get_resource_data",
+		Arguments: map[string]any{"url": "https://127.0.0.1/redfish/v1/"},
+	}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	output = getFingerprint()
+	if output.PinSource != "trust_on_first_use" || output.Pin != want.SPKISHA256 {
+		t.Errorf("Expected trust-on-first-use pin %s, got source=%q pin=%q", want.SPKISHA256, output.PinSource, output.Pin)
+	}
+	if output.PinMatches == nil || !*output.PinMatches {
+		t.Errorf("Expected pin to match, got %v", output.PinMatches)
+	}
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "get_certificate_fingerprint",
+		Arguments: map[string]any{"server": "192.0.2.99"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected an error result for an unknown server")
+	}
+}
+
+func TestCreateClientConfigSharesLimiter(t *testing.T) {
+	server := newTestServer(t, config.MCPTransportStdio)
+	server.config.Redfish.MaxInFlight = 4
+
+	host := config.HostConfig{Address: "10.0.0.1"}
+	first := server.createClientConfig(host)
+	second := server.createClientConfig(host)
+	if first.Limiter == nil || first.Limiter != second.Limiter {
+		t.Fatal("Expected clients of the same host to share a limiter")
+	}
+	if first.Limiter.MaxInFlight() != 4 {
+		t.Errorf("Expected global max in flight 4, got %d", first.Limiter.MaxInFlight())
+	}
+
+	host.MaxInFlight = 1
+	overridden := server.createClientConfig(host)
+	if overridden.Limiter == first.Limiter || overridden.Limiter.MaxInFlight() != 1 {
+		t.Errorf("Expected per-host max_in_flight to replace the limiter")
+	}
+
+	other := server.createClientConfig(config.HostConfig{Address: "10.0.0.2"})
+	if other.Limiter == overridden.Limiter {
+		t.Error("Expected hosts to have separate limiters")
+	}
+
+	forwarded := server.createClientConfig(config.HostConfig{Address: "10.0.0.1", Port: 8443, MaxInFlight: 1})
+	if forwarded.Limiter == overridden.Limiter {
+		t.Error("Expected services on different ports of an address to have separate limiters")
+	}
+}
+
+func TestParseRedfishURLIPv6(t *testing.T) {
+	server := newTestServer(t, config.MCPTransportStdio)
+
+	addr, path, err := server.parseRedfishURL("https://[fe80::1%25eth1]/redfish/v1/Systems")
+	if err != nil {
+		t.Fatalf("parseRedfishURL failed: %v", err)
+	}
+	if addr != "fe80::1%eth1" || path != "/redfish/v1/Systems" {
+		t.Errorf("Expected fe80::1%%eth1 and /redfish/v1/Systems, got %s and %s", addr, path)
+	}
+}
+
+func TestParseRedfishURLDropsPort(t *testing.T) {
+	server := newTestServer(t, config.MCPTransportStdio)
+
+	for _, url := range []string{"https://10.0.0.5:8443/redfish/v1/", "https://[fe80::1%25eth1]:443/redfish/v1/"} {
+		addr, _, err := server.parseRedfishURL(url)
+		if err != nil {
+			t.Fatalf("parseRedfishURL(%s) failed: %v", url, err)
+		}
+		if addr != "10.0.0.5" && addr != "fe80::1%eth1" {
+			t.Errorf("Expected the address without port for %s, got %s", url, addr)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/config"
+)
+
+// certReloadInterval is how often certificate files are checked for changes
+const certReloadInterval = 10 * time.Second
+
+// certReloader serves the listener certificate and client CA pool, reloading
+// them when the files on disk change
+type certReloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	interval     time.Duration
+	logger       *slog.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+// newCertReloader loads the configured certificate files. It returns nil when
+// TLS is not configured.
+func newCertReloader(cfg *config.MCPConfig, logger *slog.Logger) (*certReloader, error) {
+	if !cfg.TLSEnabled() {
+		return nil, nil
+	}
+
+	r := &certReloader{
+		certFile:     cfg.TLSCertFile,
+		keyFile:      cfg.TLSKeyFile,
+		clientCAFile: cfg.TLSClientCAFile,
+		interval:     certReloadInterval,
+		logger:       logger,
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	logger.Info("MCP HTTP TLS enabled",
+		"cert_file", r.certFile,
+		"client_auth", r.clientCAFile != "")
+
+	return r, nil
+}
+
+// files returns the paths watched for changes
+func (r *certReloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+	return files
+}
+
+// load reads and parses all files, replacing the current state only if every
+// file is valid
+func (r *certReloader) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", file, err)
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate %s: %w", r.certFile, err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA bundle %s: %w", r.clientCAFile, err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no valid certificates found in client CA bundle %s", r.clientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	r.mu.Unlock()
+
+	return nil
+}
+
+// changed reports whether any watched file has a new modification time
+func (r *certReloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			// Files are often replaced non-atomically; wait for the next tick
+			continue
+		}
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+// watch polls the certificate files until ctx is cancelled
+func (r *certReloader) watch(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.load(); err != nil {
+				r.logger.Error("Failed to reload TLS certificates, keeping previous ones", "error", err)
+				continue
+			}
+			r.logger.Info("Reloaded TLS certificates", "cert_file", r.certFile)
+		}
+	}
+}
+
+// tlsConfig returns a server TLS config that picks up reloaded certificates
+// on every handshake
+func (r *certReloader) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if r.clientCAs != nil {
+				cfg.ClientCAs = r.clientCAs
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return cfg, nil
+		},
+	}
+}
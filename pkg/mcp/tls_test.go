@@ -0,0 +1,154 @@
+package mcp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/theoriginalaiexplorer/mcp-redfish-go/pkg/config"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCert issues a certificate signed by parent, or a self-signed CA when
+// parent is nil
+func newTestCert(t *testing.T, serial int64, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.ExtKeyUsage = nil
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func TestTLSListenerWithClientCertificates(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, 1, nil, x509.ExtKeyUsageServerAuth)
+	serverCert := newTestCert(t, 2, ca, x509.ExtKeyUsageServerAuth)
+	clientCert := newTestCert(t, 3, ca, x509.ExtKeyUsageClientAuth)
+
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	caFile := filepath.Join(dir, "ca.crt")
+	os.WriteFile(certFile, serverCert.certPEM, 0o600)
+	os.WriteFile(keyFile, serverCert.keyPEM, 0o600)
+	os.WriteFile(caFile, ca.certPEM, 0o600)
+
+	server := newTestServer(t, config.MCPTransportSSE)
+	reloader, err := newCertReloader(&config.MCPConfig{
+		TLSCertFile:     certFile,
+		TLSKeyFile:      keyFile,
+		TLSClientCAFile: caFile,
+	}, server.logger)
+	if err != nil {
+		t.Fatalf("newCertReloader failed: %v", err)
+	}
+	reloader.interval = 20 * time.Millisecond
+	server.certReloader = reloader
+
+	addr, stop := startTestHTTP(t, server)
+	defer stop()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientKeyPair, _ := tls.X509KeyPair(clientCert.certPEM, clientCert.keyPEM)
+
+	newClient := func(withCert bool) *http.Client {
+		tlsConfig := &tls.Config{RootCAs: roots}
+		if withCert {
+			tlsConfig.Certificates = []tls.Certificate{clientKeyPair}
+		}
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	}
+
+	// POST without a session ID reaches the SSE handler, which rejects it
+	resp, err := newClient(true).Post("https://"+addr+sseEndpoint, "application/json", nil)
+	if err != nil {
+		t.Fatalf("Request with client certificate failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 from SSE handler, got %d", resp.StatusCode)
+	}
+	if serial := resp.TLS.PeerCertificates[0].SerialNumber.Int64(); serial != 2 {
+		t.Errorf("Expected server certificate serial 2, got %d", serial)
+	}
+
+	if _, err := newClient(false).Post("https://"+addr+sseEndpoint, "application/json", nil); err == nil {
+		t.Error("Expected request without client certificate to fail")
+	}
+
+	// Replace the certificate on disk and wait for it to be picked up
+	rotated := newTestCert(t, 4, ca, x509.ExtKeyUsageServerAuth)
+	os.WriteFile(certFile, rotated.certPEM, 0o600)
+	os.WriteFile(keyFile, rotated.keyPEM, 0o600)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+	os.Chtimes(keyFile, future, future)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := newClient(true).Post("https://"+addr+sseEndpoint, "application/json", nil)
+		if err != nil {
+			t.Fatalf("Request after rotation failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.TLS.PeerCertificates[0].SerialNumber.Int64() == 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Rotated certificate was not served")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
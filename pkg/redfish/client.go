@@ -2,42 +2,56 @@ package redfish
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/avast/retry-go"
 )
 
+// logoutTimeout bounds the session DELETE issued by Close, which commonly
+// runs during shutdown
+const logoutTimeout = 5 * time.Second
+
 // Client represents a Redfish HTTP client
 type Client struct {
-	config       *ClientConfig
-	baseURL      string
-	httpClient   *http.Client
+	config     *ClientConfig
+	baseURL    string
+	httpClient *http.Client
+	backoff    *backoff
+	registries *registryCache
+	features   featureCache
+	logger     *slog.Logger
+
+	// loginMu serializes logins so that concurrent requests hitting an
+	// expired session create only one replacement
+	loginMu sync.Mutex
+	// sessionMu guards sessionToken and sessionURI
+	sessionMu    sync.RWMutex
 	sessionToken string
-	logger       *slog.Logger
+	sessionURI   string
 }
 
 // NewClient creates a new Redfish client
-func NewClient(config *ClientConfig, logger *slog.Logger) *Client {
+func NewClient(config *ClientConfig, logger *slog.Logger) (*Client, error) {
 	if logger == nil {
 		logger = slog.Default()
 	}
 
 	// Create HTTP client with TLS configuration
-	tlsConfig := &tls.Config{
-		MinVersion:         tls.VersionTLS12,
-		InsecureSkipVerify: config.InsecureSkipVerify,
-	}
-
-	if config.TLSServerCACert != "" {
-		// TODO: Load custom CA certificate
-		logger.Warn("Custom CA certificate support not yet implemented")
+	tlsConfig, err := buildTLSConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure TLS: %w", err)
 	}
 
 	httpClient := &http.Client{
@@ -47,23 +61,39 @@ func NewClient(config *ClientConfig, logger *slog.Logger) *Client {
 		},
 	}
 
-	baseURL := fmt.Sprintf("https://%s:%d", config.Address, config.Port)
+	// JoinHostPort brackets IPv6 addresses; url.URL encodes their zone
+	baseURL := (&url.URL{
+		Scheme: "https",
+		Host:   net.JoinHostPort(config.Address, strconv.Itoa(config.Port)),
+	}).String()
 
-	return &Client{
+	client := &Client{
 		config:     config,
 		baseURL:    baseURL,
 		httpClient: httpClient,
+		backoff:    newBackoff(config),
 		logger:     logger,
 	}
+	client.registries = newRegistryCache(client)
+	return client, nil
 }
 
 // Login authenticates with the Redfish service
 func (c *Client) Login() error {
+	return c.LoginContext(context.Background())
+}
+
+// LoginContext authenticates with the Redfish service, aborting if ctx is done
+func (c *Client) LoginContext(ctx context.Context) error {
 	switch c.config.AuthMethod {
 	case AuthMethodBasic:
 		return c.loginBasic()
 	case AuthMethodSession:
-		return c.loginSession()
+		c.loginMu.Lock()
+		defer c.loginMu.Unlock()
+		return c.loginSession(ctx)
+	case AuthMethodCertificate:
+		return c.loginCertificate()
 	default:
 		return fmt.Errorf("unsupported auth method: %s", c.config.AuthMethod)
 	}
@@ -76,8 +106,16 @@ func (c *Client) loginBasic() error {
 	return nil
 }
 
-// loginSession performs session-based authentication
-func (c *Client) loginSession() error {
+// loginCertificate performs client certificate authentication
+func (c *Client) loginCertificate() error {
+	// The certificate is presented during the TLS handshake of every request
+	c.logger.Info("Using client certificate authentication")
+	return nil
+}
+
+// loginSession performs session-based authentication. Callers must hold
+// c.loginMu.
+func (c *Client) loginSession(ctx context.Context) error {
 	sessionURL := c.baseURL + "/redfish/v1/SessionService/Sessions"
 
 	loginData := map[string]interface{}{
@@ -90,25 +128,36 @@ func (c *Client) loginSession() error {
 		return fmt.Errorf("failed to marshal login data: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", sessionURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", sessionURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("failed to create login request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
+	release, err := c.acquireSlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("login request failed: %w", err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return &RedfishError{
+			Message: fmt.Sprintf("login request failed: %v", err),
+			Code:    0, // Network error
+		}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return &RedfishError{
-			Message: fmt.Sprintf("login failed with status %d: %s", resp.StatusCode, string(body)),
-			Code:    resp.StatusCode,
-		}
+		redfishErr := newHTTPError(resp.StatusCode, body)
+		redfishErr.Message = "login failed with " + redfishErr.Message
+		return redfishErr
 	}
 
 	// Extract session token from response
@@ -117,67 +166,160 @@ func (c *Client) loginSession() error {
 		return fmt.Errorf("failed to decode session response: %w", err)
 	}
 
-	// Extract X-Auth-Token from response headers
-	if token := resp.Header.Get("X-Auth-Token"); token != "" {
-		c.sessionToken = token
-		c.logger.Info("Session authentication successful")
-		return nil
+	// Extract X-Auth-Token from response headers, falling back to the body
+	token := resp.Header.Get("X-Auth-Token")
+	if token == "" {
+		token, _ = sessionResp["token"].(string)
+	}
+	if token == "" {
+		return fmt.Errorf("no session token found in response")
+	}
+
+	sessionURI := sessionLocation(resp.Header.Get("Location"), sessionResp)
+	if sessionURI == "" {
+		c.logger.Warn("Session created without a Location, it cannot be deleted on logout")
 	}
 
-	// Fallback: try to extract from response body
-	if token, ok := sessionResp["token"].(string); ok {
-		c.sessionToken = token
-		c.logger.Info("Session authentication successful")
+	c.sessionMu.Lock()
+	c.sessionToken = token
+	c.sessionURI = sessionURI
+	c.sessionMu.Unlock()
+
+	c.logger.Info("Session authentication successful", "session", sessionURI)
+	return nil
+}
+
+// currentToken returns the active session token
+func (c *Client) currentToken() string {
+	c.sessionMu.RLock()
+	defer c.sessionMu.RUnlock()
+	return c.sessionToken
+}
+
+// reauthenticate replaces an expired session. If another request already
+// replaced staleToken, it returns without logging in again.
+func (c *Client) reauthenticate(ctx context.Context, staleToken string) error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	if c.currentToken() != staleToken {
 		return nil
 	}
 
-	return fmt.Errorf("no session token found in response")
+	c.logger.Info("Redfish session expired, re-authenticating")
+	return c.loginSession(ctx)
 }
 
-// Logout ends the session
+// sessionLocation returns the path of the created session resource from the
+// Location header or the @odata.id of the response body
+func sessionLocation(location string, sessionResp map[string]interface{}) string {
+	if location == "" {
+		location, _ = sessionResp["@odata.id"].(string)
+	}
+	if location == "" {
+		return ""
+	}
+
+	// Location may be an absolute URL; requests are always made to baseURL
+	parsed, err := url.Parse(location)
+	if err != nil {
+		return ""
+	}
+	return parsed.RequestURI()
+}
+
+// Logout ends the session by deleting the session resource. Failures are
+// logged rather than returned, since the session will eventually expire.
 func (c *Client) Logout() error {
-	if c.sessionToken == "" {
+	return c.LogoutContext(context.Background())
+}
+
+// LogoutContext is Logout with a context bounding the DELETE request
+func (c *Client) LogoutContext(ctx context.Context) error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	c.sessionMu.RLock()
+	token, sessionURI := c.sessionToken, c.sessionURI
+	c.sessionMu.RUnlock()
+
+	if token == "" {
 		return nil // No session to logout from
 	}
 
-	// For session auth, we don't need to explicitly logout
-	// The session will expire on the server side
+	if sessionURI != "" {
+		if _, err := c.doRequest(ctx, "DELETE", sessionURI, nil); err != nil {
+			c.logger.Warn("Failed to delete Redfish session",
+				"session", sessionURI,
+				"error", err)
+		} else {
+			c.logger.Info("Session deleted", "session", sessionURI)
+		}
+	}
+
+	c.sessionMu.Lock()
 	c.sessionToken = ""
-	c.logger.Info("Session cleared")
+	c.sessionURI = ""
+	c.sessionMu.Unlock()
 	return nil
 }
 
 // Get performs a GET request to the Redfish API
 func (c *Client) Get(resourcePath string) (*RedfishResponse, error) {
-	return c.request("GET", resourcePath, nil)
+	return c.GetContext(context.Background(), resourcePath)
+}
+
+// GetContext performs a GET request to the Redfish API
+func (c *Client) GetContext(ctx context.Context, resourcePath string) (*RedfishResponse, error) {
+	return c.request(ctx, "GET", resourcePath, nil)
 }
 
 // Post performs a POST request to the Redfish API
 func (c *Client) Post(resourcePath string, data interface{}) (*RedfishResponse, error) {
+	return c.PostContext(context.Background(), resourcePath, data)
+}
+
+// PostContext performs a POST request to the Redfish API
+func (c *Client) PostContext(ctx context.Context, resourcePath string, data interface{}) (*RedfishResponse, error) {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request data: %w", err)
 	}
-	return c.request("POST", resourcePath, jsonData)
+	return c.request(ctx, "POST", resourcePath, jsonData)
 }
 
 // Patch performs a PATCH request to the Redfish API
 func (c *Client) Patch(resourcePath string, data interface{}) (*RedfishResponse, error) {
+	return c.PatchContext(context.Background(), resourcePath, data)
+}
+
+// PatchContext performs a PATCH request to the Redfish API
+func (c *Client) PatchContext(ctx context.Context, resourcePath string, data interface{}) (*RedfishResponse, error) {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request data: %w", err)
 	}
-	return c.request("PATCH", resourcePath, jsonData)
+	return c.request(ctx, "PATCH", resourcePath, jsonData)
 }
 
 // Delete performs a DELETE request to the Redfish API
 func (c *Client) Delete(resourcePath string) (*RedfishResponse, error) {
-	return c.request("DELETE", resourcePath, nil)
+	return c.DeleteContext(context.Background(), resourcePath)
+}
+
+// DeleteContext performs a DELETE request to the Redfish API
+func (c *Client) DeleteContext(ctx context.Context, resourcePath string) (*RedfishResponse, error) {
+	return c.request(ctx, "DELETE", resourcePath, nil)
 }
 
 // GetWithHeaders performs a GET request and returns both data and headers
 func (c *Client) GetWithHeaders(resourcePath string) (*RedfishResponse, error) {
-	resp, err := c.request("GET", resourcePath, nil)
+	return c.GetWithHeadersContext(context.Background(), resourcePath)
+}
+
+// GetWithHeadersContext performs a GET request and returns both data and headers
+func (c *Client) GetWithHeadersContext(ctx context.Context, resourcePath string) (*RedfishResponse, error) {
+	resp, err := c.request(ctx, "GET", resourcePath, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -202,29 +344,52 @@ func (c *Client) GetWithHeaders(resourcePath string) (*RedfishResponse, error) {
 	return resp, nil
 }
 
-// request performs an HTTP request with retry logic
-func (c *Client) request(method, resourcePath string, body []byte) (*RedfishResponse, error) {
+// request performs an HTTP request with retry logic. Retry delays end early
+// when ctx is done or the configured retry deadline passes.
+func (c *Client) request(parent context.Context, method, resourcePath string, body []byte) (*RedfishResponse, error) {
 	var lastResp *RedfishResponse
 	var lastErr error
+	reauthenticated := false
+
+	ctx := parent
+	if c.config.RetryDeadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, c.config.RetryDeadline)
+		defer cancel()
+	}
 
 	retryConfig := []retry.Option{
 		retry.Attempts(uint(c.config.MaxRetries + 1)), // +1 because Attempts includes initial attempt
-		retry.Delay(c.config.InitialDelay),
-		retry.MaxDelay(c.config.MaxDelay),
-		retry.DelayType(retry.BackOffDelay),
+		retry.DelayType(func(n uint, err error, _ *retry.Config) time.Duration {
+			// Wait at least as long as the service asked
+			return max(c.backoff.delay(n), retryAfter(err))
+		}),
+		retry.Context(ctx),
 		retry.RetryIf(func(err error) bool {
-			return IsRetryable(err)
+			// RetryIf replaces retry-go's own check for unrecoverable errors
+			return retry.IsRecoverable(err) && IsRetryable(err) && c.canWait(ctx, retryAfter(err))
 		}),
 		retry.OnRetry(func(n uint, err error) {
 			c.logger.Warn("Redfish request failed, retrying",
 				"attempt", n+1,
+				"class", Classify(err),
 				"error", err)
 		}),
 	}
 
 	err := retry.Do(
 		func() error {
-			resp, err := c.doRequest(method, resourcePath, body)
+			token := c.currentToken()
+			resp, err := c.doRequest(ctx, method, resourcePath, body)
+			if err != nil && !reauthenticated && c.sessionExpired(err) {
+				// Log in again once and replay the request
+				reauthenticated = true
+				if loginErr := c.reauthenticate(ctx, token); loginErr != nil {
+					lastErr = fmt.Errorf("re-authentication failed: %w", loginErr)
+					return retry.Unrecoverable(lastErr)
+				}
+				resp, err = c.doRequest(ctx, method, resourcePath, body)
+			}
 			if err != nil {
 				lastErr = err
 				return err
@@ -236,14 +401,61 @@ func (c *Client) request(method, resourcePath string, body []byte) (*RedfishResp
 	)
 
 	if err != nil {
+		if ctxErr := parent.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("Redfish request %s %s aborted: %w", method, resourcePath, ctxErr)
+		}
+		if ctx.Err() != nil {
+			// The caller is still waiting, so the deadline says something
+			// about the service: keep the class of the last failure, or
+			// report a network error when the service never answered
+			cause := lastErr
+			if cause == nil || errors.Is(cause, context.DeadlineExceeded) {
+				cause = &RedfishError{
+					Message: fmt.Sprintf("no response: %v", ctx.Err()),
+					Code:    0, // Network error
+				}
+			}
+			return nil, fmt.Errorf("Redfish request %s %s exceeded retry deadline of %s: %w",
+				method, resourcePath, c.config.RetryDeadline, cause)
+		}
 		return nil, lastErr
 	}
 
 	return lastResp, nil
 }
 
+// canWait reports whether a Retry-After wait fits within the maximum retry
+// delay and the remaining time of ctx. Retrying earlier than the service
+// asked would only be throttled again.
+func (c *Client) canWait(ctx context.Context, wait time.Duration) bool {
+	if wait == 0 {
+		return true
+	}
+	if c.config.MaxDelay > 0 && wait > c.config.MaxDelay {
+		c.logger.Warn("Redfish service asked to retry later than the maximum delay, giving up",
+			"retry_after", wait,
+			"max_delay", c.config.MaxDelay)
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		c.logger.Warn("Redfish service asked to retry after the request deadline, giving up",
+			"retry_after", wait)
+		return false
+	}
+	return true
+}
+
+// sessionExpired reports whether err means the session token was rejected
+func (c *Client) sessionExpired(err error) bool {
+	if c.config.AuthMethod != AuthMethodSession {
+		return false
+	}
+	var redfishErr *RedfishError
+	return errors.As(err, &redfishErr) && redfishErr.Code == http.StatusUnauthorized
+}
+
 // doRequest performs a single HTTP request
-func (c *Client) doRequest(method, resourcePath string, body []byte) (*RedfishResponse, error) {
+func (c *Client) doRequest(ctx context.Context, method, resourcePath string, body []byte) (*RedfishResponse, error) {
 	fullURL := c.baseURL + resourcePath
 	if !strings.HasPrefix(resourcePath, "/") {
 		fullURL = c.baseURL + "/" + resourcePath
@@ -258,7 +470,7 @@ func (c *Client) doRequest(method, resourcePath string, body []byte) (*RedfishRe
 		bodyReader = bytes.NewReader(body)
 	}
 
-	req, err := http.NewRequest(method, fullURL, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -272,9 +484,19 @@ func (c *Client) doRequest(method, resourcePath string, body []byte) (*RedfishRe
 		return nil, fmt.Errorf("failed to add auth headers: %w", err)
 	}
 
+	// Wait for a free request slot on the host
+	release, err := c.acquireSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	// Make the request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, &RedfishError{
 			Message: fmt.Sprintf("HTTP request failed: %v", err),
 			Code:    0, // Network error
@@ -285,7 +507,14 @@ func (c *Client) doRequest(method, resourcePath string, body []byte) (*RedfishRe
 	// Read response body
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		// The connection broke mid-response
+		return nil, &RedfishError{
+			Message: fmt.Sprintf("failed to read response body: %v", err),
+			Code:    0, // Network error
+		}
 	}
 
 	// Parse JSON response
@@ -300,10 +529,9 @@ func (c *Client) doRequest(method, resourcePath string, body []byte) (*RedfishRe
 
 	// Check for HTTP errors
 	if resp.StatusCode >= 400 {
-		return nil, &RedfishError{
-			Message: fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(respBody)),
-			Code:    resp.StatusCode,
-		}
+		redfishErr := newHTTPError(resp.StatusCode, respBody)
+		redfishErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return nil, redfishErr
 	}
 
 	return &RedfishResponse{
@@ -313,6 +541,18 @@ func (c *Client) doRequest(method, resourcePath string, body []byte) (*RedfishRe
 	}, nil
 }
 
+// acquireSlot waits for the host's request limiter, if any
+func (c *Client) acquireSlot(ctx context.Context) (func(), error) {
+	if c.config.Limiter == nil {
+		return func() {}, nil
+	}
+	release, err := c.config.Limiter.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s not sent: %w", c.config.Address, err)
+	}
+	return release, nil
+}
+
 // addAuthHeaders adds authentication headers to the request
 func (c *Client) addAuthHeaders(req *http.Request) error {
 	switch c.config.AuthMethod {
@@ -321,8 +561,8 @@ func (c *Client) addAuthHeaders(req *http.Request) error {
 			req.SetBasicAuth(c.config.Username, c.config.Password)
 		}
 	case AuthMethodSession:
-		if c.sessionToken != "" {
-			req.Header.Set("X-Auth-Token", c.sessionToken)
+		if token := c.currentToken(); token != "" {
+			req.Header.Set("X-Auth-Token", token)
 		}
 	}
 	return nil
@@ -330,7 +570,10 @@ func (c *Client) addAuthHeaders(req *http.Request) error {
 
 // Close closes the client and cleans up resources
 func (c *Client) Close() error {
-	c.Logout()
+	ctx, cancel := context.WithTimeout(context.Background(), logoutTimeout)
+	defer cancel()
+
+	c.LogoutContext(ctx)
 	if c.httpClient != nil {
 		c.httpClient.CloseIdleConnections()
 	}
@@ -0,0 +1,334 @@
+package redfish
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeBMC is a minimal Redfish service with session management
+type fakeBMC struct {
+	mu sync.Mutex
+	// sessions maps tokens to session IDs
+	sessions map[string]string
+	created  int
+	deleted  int
+	// absoluteLocation returns a full URL in the Location header
+	absoluteLocation bool
+	// rejectAll makes every resource request fail with 401
+	rejectAll bool
+	baseURL   string
+}
+
+func newFakeBMC(t *testing.T) (*fakeBMC, *ClientConfig) {
+	t.Helper()
+
+	bmc := &fakeBMC{sessions: make(map[string]string)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /redfish/v1/SessionService/Sessions", bmc.createSession)
+	mux.HandleFunc("DELETE /redfish/v1/SessionService/Sessions/{id}", bmc.deleteSession)
+	mux.HandleFunc("GET /redfish/v1/", func(w http.ResponseWriter, r *http.Request) {
+		if !bmc.authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"Id": "RootService"}`))
+	})
+
+	server, config := newTestBMC(t, mux)
+	bmc.baseURL = server.URL
+	config.AuthMethod = AuthMethodSession
+	config.Username = "admin"
+	config.Password = "password"
+	config.InsecureSkipVerify = true
+	return bmc, config
+}
+
+func (b *fakeBMC) createSession(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	b.created++
+	id := strconv.Itoa(b.created)
+	token := "token-" + id
+	b.sessions[token] = id
+	b.mu.Unlock()
+
+	location := "/redfish/v1/SessionService/Sessions/" + id
+	if b.absoluteLocation {
+		location = b.baseURL + location
+	}
+
+	w.Header().Set("X-Auth-Token", token)
+	w.Header().Set("Location", location)
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(`{"@odata.id": "/redfish/v1/SessionService/Sessions/` + id + `", "Id": "` + id + `"}`))
+}
+
+func (b *fakeBMC) deleteSession(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := r.PathValue("id")
+	token := r.Header.Get("X-Auth-Token")
+	if b.sessions[token] != id {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	delete(b.sessions, token)
+	b.deleted++
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *fakeBMC) authorized(r *http.Request) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.sessions[r.Header.Get("X-Auth-Token")]
+	return ok && !b.rejectAll
+}
+
+func (b *fakeBMC) activeSessions() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.sessions)
+}
+
+func TestLogoutDeletesSession(t *testing.T) {
+	for _, absolute := range []bool{false, true} {
+		bmc, config := newFakeBMC(t)
+		bmc.absoluteLocation = absolute
+
+		client, err := NewClient(config, testLogger())
+		if err != nil {
+			t.Fatalf("NewClient failed: %v", err)
+		}
+		if err := client.Login(); err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+		if _, err := client.Get("/redfish/v1/"); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if n := bmc.activeSessions(); n != 1 {
+			t.Fatalf("Expected 1 active session, got %d", n)
+		}
+
+		if err := client.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		if n := bmc.activeSessions(); n != 0 {
+			t.Errorf("Expected session to be deleted (absolute=%v), %d still active", absolute, n)
+		}
+
+		// A second logout is a no-op
+		client.Logout()
+		if bmc.deleted != 1 {
+			t.Errorf("Expected exactly one DELETE, got %d", bmc.deleted)
+		}
+	}
+}
+
+func TestLogoutErrorIsNotFatal(t *testing.T) {
+	bmc, config := newFakeBMC(t)
+
+	client, _ := NewClient(config, testLogger())
+	if err := client.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	// Simulate the BMC having already expired the session
+	bmc.mu.Lock()
+	bmc.sessions = make(map[string]string)
+	bmc.mu.Unlock()
+
+	if err := client.Logout(); err != nil {
+		t.Errorf("Expected logout failure to be logged, not returned: %v", err)
+	}
+}
+
+func TestReauthenticateOnExpiredSession(t *testing.T) {
+	bmc, config := newFakeBMC(t)
+
+	client, _ := NewClient(config, testLogger())
+	if err := client.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	// Expire every session on the BMC
+	bmc.mu.Lock()
+	bmc.sessions = make(map[string]string)
+	bmc.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Get("/redfish/v1/"); err != nil {
+				t.Errorf("Get after expiry failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	bmc.mu.Lock()
+	created := bmc.created
+	bmc.mu.Unlock()
+	if created != 2 {
+		t.Errorf("Expected exactly one replacement session, got %d sessions created", created)
+	}
+}
+
+func TestReauthenticateOnlyOnce(t *testing.T) {
+	bmc, config := newFakeBMC(t)
+	bmc.rejectAll = true
+
+	client, _ := NewClient(config, testLogger())
+	if err := client.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	// A resource that keeps returning 401 must not cause a login loop
+	if _, err := client.Get("/redfish/v1/"); err == nil {
+		t.Fatal("Expected persistent 401 to be returned")
+	}
+
+	bmc.mu.Lock()
+	created := bmc.created
+	bmc.mu.Unlock()
+	if created != 2 {
+		t.Errorf("Expected a single re-authentication, got %d sessions created", created)
+	}
+}
+
+func TestFailedReauthenticationIsNotRetried(t *testing.T) {
+	var logins, gets atomic.Int32
+	_, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if logins.Add(1) > 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("X-Auth-Token", "token")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+			return
+		}
+		gets.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	config.InsecureSkipVerify = true
+	config.AuthMethod = AuthMethodSession
+	config.Username = "admin"
+	config.Password = "password"
+	config.MaxRetries = 3
+	config.InitialDelay = time.Millisecond
+
+	client, _ := NewClient(config, testLogger())
+	if err := client.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	_, err := client.Get("/redfish/v1/")
+	if err == nil || !strings.Contains(err.Error(), "re-authentication failed") {
+		t.Fatalf("Expected re-authentication error, got: %v", err)
+	}
+	if n := gets.Load(); n != 1 {
+		t.Errorf("Expected the request not to be resent after a failed login, got %d requests", n)
+	}
+}
+
+func TestContextCancelsRetries(t *testing.T) {
+	_, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	config.InsecureSkipVerify = true
+	config.MaxRetries = 3
+	config.InitialDelay = 10 * time.Second
+
+	client, _ := NewClient(config, testLogger())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetContext(ctx, "/redfish/v1/")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Retry sleep was not cancelled, took %v", elapsed)
+	}
+}
+
+func TestContextAbortsInFlightRequest(t *testing.T) {
+	_, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Simulate a hung BMC
+		<-r.Context().Done()
+	}))
+	config.InsecureSkipVerify = true
+	config.MaxRetries = 3
+
+	client, _ := NewClient(config, testLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := client.GetContext(ctx, "/redfish/v1/")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("In-flight request was not aborted, took %v", elapsed)
+	}
+}
+
+func TestHTTPErrorParsesExtendedInfo(t *testing.T) {
+	body := []byte(`{"error": {
+		"code": "Base.1.8.GeneralError",
+		"message": "A general error has occurred. See ExtendedInfo for more information.",
+		"@Message.ExtendedInfo": [
+			{
+				"MessageId": "Base.1.8.PropertyValueTypeError",
+				"Message": "The value 5 for the property AssetTag is of a different type than the property can accept.",
+				"MessageArgs": [5, "AssetTag"],
+				"MessageSeverity": "Warning",
+				"Resolution": "Correct the value for the property in the request body and resubmit the request if the operation failed.",
+				"RelatedProperties": ["#/AssetTag"]
+			},
+			{"MessageId": "Base.1.8.ActionNotSupported", "Severity": "Critical"}
+		]
+	}}`)
+
+	err := newHTTPError(http.StatusBadRequest, body)
+	if err.ErrorCode != "Base.1.8.GeneralError" {
+		t.Errorf("Expected error code Base.1.8.GeneralError, got %q", err.ErrorCode)
+	}
+	if len(err.ExtendedInfo) != 2 {
+		t.Fatalf("Expected 2 extended info entries, got %d", len(err.ExtendedInfo))
+	}
+
+	first := err.ExtendedInfo[0]
+	if first.Severity != "Warning" || first.Resolution == "" {
+		t.Errorf("Unexpected first entry: %+v", first)
+	}
+	if len(first.MessageArgs) != 2 || first.MessageArgs[0] != "5" {
+		t.Errorf("Expected numeric message arg to be stringified, got %v", first.MessageArgs)
+	}
+	if err.ExtendedInfo[1].Severity != "Critical" {
+		t.Errorf("Expected deprecated Severity to be used, got %q", err.ExtendedInfo[1].Severity)
+	}
+	if !strings.Contains(err.Error(), "AssetTag") || strings.Contains(err.Error(), "{") {
+		t.Errorf("Expected readable message, got %q", err.Error())
+	}
+
+	raw := newHTTPError(http.StatusBadGateway, []byte("upstream down"))
+	if raw.Error() != "HTTP 502: upstream down" || raw.ExtendedInfo != nil {
+		t.Errorf("Expected raw body fallback, got %q", raw.Error())
+	}
+}
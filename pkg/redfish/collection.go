@@ -0,0 +1,125 @@
+package redfish
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DefaultMaxMembers caps the members gathered by GetAllMembersContext when
+// no limit is given
+const DefaultMaxMembers = 1000
+
+const (
+	membersKey  = "Members"
+	nextLinkKey = "Members@odata.nextLink"
+)
+
+// GetAllMembersContext fetches a collection and follows its
+// Members@odata.nextLink pages, returning the first page with the Members of
+// all pages merged. At most maxMembers members are gathered (DefaultMaxMembers
+// when zero or negative); truncated reports whether more were available.
+func (c *Client) GetAllMembersContext(ctx context.Context, resourcePath string, maxMembers int) (resp *RedfishResponse, truncated bool, err error) {
+	if maxMembers <= 0 {
+		maxMembers = DefaultMaxMembers
+	}
+
+	resp, err = c.GetWithHeadersContext(ctx, resourcePath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	first, ok := resp.Data.(map[string]interface{})
+	if !ok {
+		return resp, false, nil
+	}
+	members, _ := first[membersKey].([]interface{})
+
+	page := first
+	seen := map[string]bool{resourcePath: true}
+	for {
+		if len(members) > maxMembers {
+			members = members[:maxMembers]
+			truncated = true
+			break
+		}
+
+		next, _ := page[nextLinkKey].(string)
+		if next == "" {
+			break
+		}
+		if len(members) == maxMembers {
+			truncated = true
+			break
+		}
+
+		nextPath, err := c.localPath(next)
+		if err != nil {
+			return nil, false, err
+		}
+		if seen[nextPath] {
+			return nil, false, fmt.Errorf("collection %s links back to already fetched page %s", resourcePath, nextPath)
+		}
+		seen[nextPath] = true
+
+		nextResp, err := c.GetContext(ctx, nextPath)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to fetch collection page %s: %w", nextPath, err)
+		}
+		page, ok = nextResp.Data.(map[string]interface{})
+		if !ok {
+			return nil, false, fmt.Errorf("collection page %s is not a JSON object", nextPath)
+		}
+		pageMembers, _ := page[membersKey].([]interface{})
+		members = append(members, pageMembers...)
+	}
+
+	merged := make(map[string]interface{}, len(first))
+	for key, value := range first {
+		merged[key] = value
+	}
+	merged[membersKey] = members
+	delete(merged, nextLinkKey)
+	resp.Data = merged
+
+	return resp, truncated, nil
+}
+
+// localPath converts a link from the service into a request path, rejecting
+// absolute links to another host, port or scheme, since requests always go
+// to the client's base URL
+func (c *Client) localPath(link string) (string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("invalid link %q: %w", link, err)
+	}
+
+	if u.IsAbs() {
+		base, _ := url.Parse(c.baseURL)
+		if !strings.EqualFold(u.Scheme, base.Scheme) || u.Hostname() != base.Hostname() || effectivePort(u) != effectivePort(base) {
+			return "", fmt.Errorf("link %q points to another service", link)
+		}
+	}
+
+	path := u.EscapedPath()
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path, nil
+}
+
+// effectivePort returns the port of u, or the default port of its scheme
+func effectivePort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	default:
+		return ""
+	}
+}
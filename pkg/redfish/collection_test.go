@@ -0,0 +1,147 @@
+package redfish
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// newPagedBMC serves /redfish/v1/Entries as total members split into pages
+// of pageSize, linking pages with Members@odata.nextLink
+func newPagedBMC(t *testing.T, total, pageSize int, nextLink func(skip int) string) *ClientConfig {
+	t.Helper()
+
+	server, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/redfish/v1/Entries" {
+			http.NotFound(w, r)
+			return
+		}
+		skip := 0
+		fmt.Sscanf(r.URL.Query().Get("$skip"), "%d", &skip)
+
+		var members []string
+		for i := skip; i < total && i < skip+pageSize; i++ {
+			members = append(members, fmt.Sprintf(`{"@odata.id": "/redfish/v1/Entries/%d"}`, i))
+		}
+		link := ""
+		if skip+pageSize < total {
+			link = fmt.Sprintf(`, "Members@odata.nextLink": %q`, nextLink(skip+pageSize))
+		}
+		fmt.Fprintf(w, `{"Name": "Entries", "Members@odata.count": %d, "Members": [%s]%s}`,
+			total, strings.Join(members, ","), link)
+	}))
+	config.TLSServerCACert = certPEM(server)
+	return config
+}
+
+func TestGetAllMembersFollowsNextLinks(t *testing.T) {
+	config := newPagedBMC(t, 5, 2, func(skip int) string {
+		return fmt.Sprintf("/redfish/v1/Entries?$skip=%d", skip)
+	})
+	client, err := NewClient(config, testLogger())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	resp, truncated, err := client.GetAllMembersContext(context.Background(), "/redfish/v1/Entries", 0)
+	if err != nil {
+		t.Fatalf("GetAllMembersContext failed: %v", err)
+	}
+	if truncated {
+		t.Error("Expected complete collection")
+	}
+
+	data := resp.Data.(map[string]interface{})
+	members := data["Members"].([]interface{})
+	if len(members) != 5 {
+		t.Fatalf("Expected 5 merged members, got %d", len(members))
+	}
+	last := members[4].(map[string]interface{})["@odata.id"]
+	if last != "/redfish/v1/Entries/4" {
+		t.Errorf("Expected members in page order, last was %v", last)
+	}
+	if _, ok := data["Members@odata.nextLink"]; ok {
+		t.Error("Expected nextLink to be removed from the merged collection")
+	}
+	if data["Name"] != "Entries" {
+		t.Error("Expected first page properties to be kept")
+	}
+}
+
+func TestGetAllMembersCap(t *testing.T) {
+	config := newPagedBMC(t, 10, 3, func(skip int) string {
+		return fmt.Sprintf("/redfish/v1/Entries?$skip=%d", skip)
+	})
+	client, err := NewClient(config, testLogger())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	for _, limit := range []int{4, 6} {
+		resp, truncated, err := client.GetAllMembersContext(context.Background(), "/redfish/v1/Entries", limit)
+		if err != nil {
+			t.Fatalf("GetAllMembersContext failed: %v", err)
+		}
+		members := resp.Data.(map[string]interface{})["Members"].([]interface{})
+		if len(members) != limit || !truncated {
+			t.Errorf("Limit %d: expected %d members and truncation, got %d (truncated=%v)", limit, limit, len(members), truncated)
+		}
+	}
+}
+
+func TestGetAllMembersRejectsBadLinks(t *testing.T) {
+	// Links are built from the address and port of the BMC being served
+	tests := map[string]func(address string, port, skip int) string{
+		"loop": func(string, int, int) string { return "/redfish/v1/Entries" },
+		"other host": func(_ string, _, skip int) string {
+			return fmt.Sprintf("https://evil.example.com/redfish/v1/Entries?$skip=%d", skip)
+		},
+		"other port": func(address string, port, skip int) string {
+			return fmt.Sprintf("https://%s:%d/redfish/v1/Entries?$skip=%d", address, port+1, skip)
+		},
+		"other scheme": func(address string, port, skip int) string {
+			return fmt.Sprintf("http://%s:%d/redfish/v1/Entries?$skip=%d", address, port, skip)
+		},
+		"default port": func(address string, _, skip int) string {
+			return fmt.Sprintf("https://%s/redfish/v1/Entries?$skip=%d", address, skip)
+		},
+	}
+
+	for name, nextLink := range tests {
+		t.Run(name, func(t *testing.T) {
+			var config *ClientConfig
+			config = newPagedBMC(t, 4, 2, func(skip int) string {
+				return nextLink(config.Address, config.Port, skip)
+			})
+			client, err := NewClient(config, testLogger())
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+			defer client.Close()
+
+			if _, _, err := client.GetAllMembersContext(context.Background(), "/redfish/v1/Entries", 0); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestLocalPathMatchesDefaultPorts(t *testing.T) {
+	client := &Client{baseURL: "https://10.0.0.1:443"}
+
+	for _, link := range []string{"https://10.0.0.1/redfish/v1/Entries?$skip=2", "HTTPS://10.0.0.1:443/redfish/v1/Entries?$skip=2"} {
+		path, err := client.localPath(link)
+		if err != nil || path != "/redfish/v1/Entries?$skip=2" {
+			t.Errorf("Expected %s to be accepted, got %q (%v)", link, path, err)
+		}
+	}
+	for _, link := range []string{"https://10.0.0.1:8443/redfish/v1/Entries", "http://10.0.0.1/redfish/v1/Entries"} {
+		if _, err := client.localPath(link); err == nil {
+			t.Errorf("Expected %s to be rejected", link)
+		}
+	}
+}
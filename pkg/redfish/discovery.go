@@ -1,12 +1,16 @@
 package redfish
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -15,12 +19,31 @@ const (
 	ssdpPort = 1900
 	ssdpMX   = 2
 	ssdpST   = "urn:dmtf-org:service:redfish-rest:1"
+
+	// ssdpAddrLinkLocal and ssdpAddrSiteLocal are the IPv6 SSDP groups
+	ssdpAddrLinkLocal = "ff02::c"
+	ssdpAddrSiteLocal = "ff05::c"
+)
+
+// DefaultSSDPMaxAge is the lifetime of a discovered host whose response
+// carries no CACHE-CONTROL max-age, the minimum UPnP allows
+const DefaultSSDPMaxAge = 1800 * time.Second
+
+var (
+	maxAgeRegex = regexp.MustCompile(`(?i)\bmax-age\s*=\s*"?(\d+)`)
+	uuidRegex   = regexp.MustCompile(`(?i)^uuid:([^:]+)`)
 )
 
 // SSDPDiscovery handles SSDP discovery of Redfish endpoints
 type SSDPDiscovery struct {
 	timeout time.Duration
-	logger  *slog.Logger
+	// target receives the M-SEARCH request instead of the SSDP multicast
+	// groups when set with SetTarget
+	target *net.UDPAddr
+	// interfaces are interface names or CIDRs to search on
+	interfaces []string
+	ipv6       bool
+	logger     *slog.Logger
 }
 
 // NewSSDPDiscovery creates a new SSDP discovery instance
@@ -34,15 +57,100 @@ func NewSSDPDiscovery(timeout time.Duration, logger *slog.Logger) *SSDPDiscovery
 	}
 }
 
+// SetTarget sends M-SEARCH requests to addr instead of the SSDP multicast
+// groups, e.g. to query a single device by unicast
+func (d *SSDPDiscovery) SetTarget(addr *net.UDPAddr) {
+	d.target = addr
+}
+
+// SetInterfaces restricts searches to the given network interfaces, each
+// named or given as a CIDR matching its addresses. Without interfaces the
+// IPv4 search uses the default route.
+func (d *SSDPDiscovery) SetInterfaces(interfaces []string) {
+	d.interfaces = interfaces
+}
+
+// SetIPv6 enables searching the IPv6 link-local and site-local SSDP groups.
+// Interfaces selected by an IPv6 CIDR are searched over IPv6 regardless.
+func (d *SSDPDiscovery) SetIPv6(enabled bool) {
+	d.ipv6 = enabled
+}
+
+// ssdpSearch is one M-SEARCH socket: the groups it is sent to and the local
+// address that pins it to an interface
+type ssdpSearch struct {
+	iface   string
+	network string
+	local   *net.UDPAddr
+	groups  []*net.UDPAddr
+}
+
 // Discover performs SSDP M-SEARCH and returns discovered Redfish endpoints
 func (d *SSDPDiscovery) Discover() ([]DiscoveredHost, error) {
+	return d.DiscoverContext(context.Background())
+}
+
+// DiscoverContext performs SSDP M-SEARCH on every selected interface and
+// address family concurrently and returns the discovered Redfish endpoints,
+// one per address. Cancelling ctx stops waiting for responses and returns
+// the context's error.
+func (d *SSDPDiscovery) DiscoverContext(ctx context.Context) ([]DiscoveredHost, error) {
 	d.logger.Info("Starting SSDP discovery")
 
-	// Create UDP socket
-	conn, err := net.DialUDP("udp", nil, &net.UDPAddr{
-		IP:   net.ParseIP(ssdpAddr),
-		Port: ssdpPort,
-	})
+	searches, err := d.searches()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([][]DiscoveredHost, len(searches))
+	errs := make([]error, len(searches))
+	var wg sync.WaitGroup
+	for i, search := range searches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = d.search(ctx, search)
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	var hosts []DiscoveredHost
+	var failed []error
+	seen := make(map[string]bool)
+	for i, search := range searches {
+		if errs[i] != nil {
+			d.logger.Warn("SSDP search failed", "interface", search.iface, "network", search.network, "error", errs[i])
+			failed = append(failed, errs[i])
+			continue
+		}
+		for _, host := range results[i] {
+			// A host answering on several groups or interfaces is kept once
+			if seen[host.Address] {
+				continue
+			}
+			seen[host.Address] = true
+			hosts = append(hosts, host)
+		}
+	}
+	if len(failed) == len(searches) {
+		return nil, errors.Join(failed...)
+	}
+
+	d.logger.Info("SSDP discovery completed", "hosts_found", len(hosts))
+	return hosts, nil
+}
+
+// search sends one M-SEARCH per group from a single socket and collects the
+// responses until the timeout
+func (d *SSDPDiscovery) search(ctx context.Context, search ssdpSearch) ([]DiscoveredHost, error) {
+	// Create an unconnected UDP socket; responses come from the devices'
+	// own addresses rather than the multicast group. Binding to an address
+	// of the interface sends the search out of that interface.
+	conn, err := net.ListenUDP(search.network, search.local)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create UDP socket: %w", err)
 	}
@@ -51,21 +159,28 @@ func (d *SSDPDiscovery) Discover() ([]DiscoveredHost, error) {
 	// Set read timeout
 	conn.SetReadDeadline(time.Now().Add(d.timeout))
 
-	// Create M-SEARCH message
-	message := fmt.Sprintf("M-SEARCH * HTTP/1.1\r\n"+
-		"HOST: %s:%d\r\n"+
-		"MAN: \"ssdp:discover\"\r\n"+
-		"MX: %d\r\n"+
-		"ST: %s\r\n\r\n",
-		ssdpAddr, ssdpPort, ssdpMX, ssdpST)
+	// Unblock the read when the context ends
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetReadDeadline(time.Now())
+	})
+	defer stop()
 
-	// Send M-SEARCH request
-	_, err = conn.Write([]byte(message))
-	if err != nil {
-		return nil, fmt.Errorf("failed to send M-SEARCH: %w", err)
+	for _, group := range search.groups {
+		// Create M-SEARCH message
+		message := fmt.Sprintf("M-SEARCH * HTTP/1.1\r\n"+
+			"HOST: %s\r\n"+
+			"MAN: \"ssdp:discover\"\r\n"+
+			"MX: %d\r\n"+
+			"ST: %s\r\n\r\n",
+			net.JoinHostPort(group.IP.String(), strconv.Itoa(group.Port)), ssdpMX, ssdpST)
+
+		// Send M-SEARCH request
+		if _, err := conn.WriteToUDP([]byte(message), group); err != nil {
+			return nil, fmt.Errorf("failed to send M-SEARCH to %s: %w", group, err)
+		}
 	}
 
-	d.logger.Info("SSDP M-SEARCH sent, waiting for responses")
+	d.logger.Info("SSDP M-SEARCH sent, waiting for responses", "interface", search.iface, "network", search.network)
 
 	var hosts []DiscoveredHost
 	buffer := make([]byte, 1024)
@@ -74,8 +189,12 @@ func (d *SSDPDiscovery) Discover() ([]DiscoveredHost, error) {
 	for {
 		n, addr, err := conn.ReadFromUDP(buffer)
 		if err != nil {
-			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-				d.logger.Info("SSDP discovery timeout reached")
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				d.logger.Debug("SSDP discovery timeout reached", "interface", search.iface, "network", search.network)
 				break
 			}
 			d.logger.Warn("Error reading SSDP response", "error", err)
@@ -83,36 +202,288 @@ func (d *SSDPDiscovery) Discover() ([]DiscoveredHost, error) {
 		}
 
 		response := string(buffer[:n])
-		alURI := d.parseAL(response)
-		if alURI != "" && d.isValidServiceRoot(alURI) {
-			host := DiscoveredHost{
-				Address:     addr.IP.String(),
-				ServiceRoot: alURI,
-			}
-			hosts = append(hosts, host)
-			d.logger.Info("Discovered Redfish endpoint",
-				"address", host.Address,
-				"service_root", host.ServiceRoot)
-		} else {
+		host, ok := d.parseResponse(response, time.Now())
+		if !ok {
 			d.logger.Debug("Received SSDP response but no valid AL header found",
-				"address", addr.IP.String())
+				"address", hostAddress(addr))
+			continue
 		}
+		host.Address = hostAddress(addr)
+		host.Interface = search.iface
+		hosts = append(hosts, host)
+		d.logger.Info("Discovered Redfish endpoint",
+			"address", host.Address,
+			"port", host.Port,
+			"service_root", host.ServiceRoot,
+			"usn", host.USN,
+			"interface", host.Interface)
 	}
 
-	d.logger.Info("SSDP discovery completed", "hosts_found", len(hosts))
 	return hosts, nil
 }
 
+// hostAddress returns the address of a responder, keeping the zone that
+// makes an IPv6 link-local address usable
+func hostAddress(addr *net.UDPAddr) string {
+	if addr.Zone != "" && addr.IP.IsLinkLocalUnicast() {
+		return addr.IP.String() + "%" + addr.Zone
+	}
+	return addr.IP.String()
+}
+
+// ssdpBinding is an interface and address family SSDP runs on, with the
+// local address used on it. iface is nil for IPv4 via the default route.
+type ssdpBinding struct {
+	iface *net.Interface
+	ip    net.IP
+	ipv6  bool
+}
+
+// name returns the interface name of the binding, empty for the default route
+func (b ssdpBinding) name() string {
+	if b.iface == nil {
+		return ""
+	}
+	return b.iface.Name
+}
+
+// bindings resolves the configured interfaces. Interfaces are looked up on
+// every call so that addresses that changed since the last call are picked
+// up.
+func (d *SSDPDiscovery) bindings() ([]ssdpBinding, error) {
+	var bindings []ssdpBinding
+	seen := make(map[string]bool)
+	add := func(iface net.Interface, ip net.IP) {
+		if ip == nil {
+			return
+		}
+		binding := ssdpBinding{iface: &iface, ip: ip, ipv6: ip.To4() == nil}
+		key := fmt.Sprintf("%s/%t", iface.Name, binding.ipv6)
+		if !seen[key] {
+			seen[key] = true
+			bindings = append(bindings, binding)
+		}
+	}
+
+	if len(d.interfaces) == 0 {
+		bindings = append(bindings, ssdpBinding{})
+
+		if d.ipv6 {
+			ifaces, err := net.Interfaces()
+			if err != nil {
+				return nil, fmt.Errorf("failed to list network interfaces: %w", err)
+			}
+			for _, iface := range ifaces {
+				if usableInterface(iface) {
+					add(iface, preferredIPv6(iface))
+				}
+			}
+		}
+		return bindings, nil
+	}
+
+	for _, selector := range d.interfaces {
+		if _, prefix, err := net.ParseCIDR(selector); err == nil {
+			ifaces, err := net.Interfaces()
+			if err != nil {
+				return nil, fmt.Errorf("failed to list network interfaces: %w", err)
+			}
+			matched := false
+			for _, iface := range ifaces {
+				if !usableInterface(iface) {
+					continue
+				}
+				for _, ip := range interfaceIPs(iface) {
+					if prefix.Contains(ip) {
+						matched = true
+						add(iface, ip)
+					}
+				}
+			}
+			if !matched {
+				d.logger.Warn("No network interface address matches discovery CIDR", "cidr", selector)
+			}
+			continue
+		}
+
+		iface, err := net.InterfaceByName(selector)
+		if err != nil {
+			d.logger.Warn("Discovery interface not found", "interface", selector, "error", err)
+			continue
+		}
+		if iface.Flags&net.FlagUp == 0 {
+			d.logger.Warn("Discovery interface is down", "interface", selector)
+			continue
+		}
+		add(*iface, firstIPv4(*iface))
+		if d.ipv6 {
+			add(*iface, preferredIPv6(*iface))
+		}
+	}
+
+	if len(bindings) == 0 {
+		return nil, fmt.Errorf("no usable network interfaces match %s", strings.Join(d.interfaces, ", "))
+	}
+	return bindings, nil
+}
+
+// searches returns the sockets to search from: the target if set, otherwise
+// one per binding
+func (d *SSDPDiscovery) searches() ([]ssdpSearch, error) {
+	if d.target != nil {
+		network := "udp4"
+		if d.target.IP.To4() == nil {
+			network = "udp6"
+		}
+		return []ssdpSearch{{network: network, groups: []*net.UDPAddr{d.target}}}, nil
+	}
+
+	bindings, err := d.bindings()
+	if err != nil {
+		return nil, err
+	}
+
+	searches := make([]ssdpSearch, 0, len(bindings))
+	for _, binding := range bindings {
+		switch {
+		case binding.iface == nil:
+			searches = append(searches, ssdpSearch{network: "udp4", groups: ssdpGroups(false, "")})
+		case binding.ipv6:
+			searches = append(searches, ipv6Search(*binding.iface, binding.ip))
+		default:
+			searches = append(searches, ipv4Search(*binding.iface, binding.ip))
+		}
+	}
+	return searches, nil
+}
+
+// ssdpGroups returns the SSDP multicast groups of an address family, scoped
+// to the named interface for IPv6
+func ssdpGroups(ipv6 bool, zone string) []*net.UDPAddr {
+	if !ipv6 {
+		return []*net.UDPAddr{{IP: net.ParseIP(ssdpAddr), Port: ssdpPort}}
+	}
+	return []*net.UDPAddr{
+		{IP: net.ParseIP(ssdpAddrLinkLocal), Port: ssdpPort, Zone: zone},
+		{IP: net.ParseIP(ssdpAddrSiteLocal), Port: ssdpPort, Zone: zone},
+	}
+}
+
+// ipv4Search searches the IPv4 group from ip on iface
+func ipv4Search(iface net.Interface, ip net.IP) ssdpSearch {
+	return ssdpSearch{
+		iface:   iface.Name,
+		network: "udp4",
+		local:   &net.UDPAddr{IP: ip},
+		groups:  ssdpGroups(false, ""),
+	}
+}
+
+// ipv6Search searches both IPv6 groups from ip on iface
+func ipv6Search(iface net.Interface, ip net.IP) ssdpSearch {
+	return ssdpSearch{
+		iface:   iface.Name,
+		network: "udp6",
+		local:   &net.UDPAddr{IP: ip, Zone: iface.Name},
+		groups:  ssdpGroups(true, iface.Name),
+	}
+}
+
+// firstIPv4 returns the first IPv4 address of an interface, or nil
+func firstIPv4(iface net.Interface) net.IP {
+	for _, ip := range interfaceIPs(iface) {
+		if ip.To4() != nil {
+			return ip
+		}
+	}
+	return nil
+}
+
+// preferredIPv6 returns the link-local IPv6 address of an interface, falling
+// back to any IPv6 address, or nil
+func preferredIPv6(iface net.Interface) net.IP {
+	var preferred net.IP
+	for _, ip := range interfaceIPs(iface) {
+		if ip.To4() == nil && (preferred == nil || ip.IsLinkLocalUnicast()) {
+			preferred = ip
+		}
+	}
+	return preferred
+}
+
+// usableInterface reports whether SSDP can be searched on an interface
+func usableInterface(iface net.Interface) bool {
+	return iface.Flags&net.FlagUp != 0 &&
+		iface.Flags&net.FlagMulticast != 0 &&
+		iface.Flags&net.FlagLoopback == 0
+}
+
+// interfaceIPs returns the unicast addresses of an interface
+func interfaceIPs(iface net.Interface) []net.IP {
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil
+	}
+	var ips []net.IP
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok {
+			ips = append(ips, ipNet.IP)
+		}
+	}
+	return ips
+}
+
+// parseResponse reads the service root, USN and lifetime of an SSDP
+// response received at now. It fails without a valid AL header.
+func (d *SSDPDiscovery) parseResponse(response string, now time.Time) (DiscoveredHost, bool) {
+	alURI := d.parseAL(response)
+	if alURI == "" || !d.isValidServiceRoot(alURI) {
+		return DiscoveredHost{}, false
+	}
+
+	host := DiscoveredHost{
+		ServiceRoot: alURI,
+		Port:        443,
+		USN:         parseHeader(response, "USN"),
+	}
+
+	if parsed, err := url.Parse(alURI); err == nil && parsed.Port() != "" {
+		if port, err := strconv.Atoi(parsed.Port()); err == nil {
+			host.Port = port
+		}
+	}
+
+	if matches := uuidRegex.FindStringSubmatch(host.USN); matches != nil {
+		host.UUID = matches[1]
+	}
+
+	// A max-age of zero would drop the host as soon as it was found, so it
+	// is treated like a missing one
+	lifetime := DefaultSSDPMaxAge
+	if matches := maxAgeRegex.FindStringSubmatch(parseHeader(response, "CACHE-CONTROL")); matches != nil {
+		if seconds, err := strconv.Atoi(matches[1]); err == nil && seconds > 0 {
+			host.MaxAge = time.Duration(seconds) * time.Second
+			lifetime = host.MaxAge
+		}
+	}
+	host.ExpiresAt = now.Add(lifetime)
+
+	return host, true
+}
+
 // parseAL extracts the AL (Alternate Location) header from SSDP response
 func (d *SSDPDiscovery) parseAL(response string) string {
-	// SSDP responses may have multiline headers, split and search each line
-	lines := strings.Split(response, "\n")
-	alRegex := regexp.MustCompile(`(?i)^AL:\s*(.+)$`)
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if matches := alRegex.FindStringSubmatch(line); len(matches) > 0 {
-			return strings.TrimSpace(matches[1])
+	return parseHeader(response, "AL")
+}
+
+// parseHeader returns the value of a header in an SSDP message, matching its
+// name case-insensitively
+func parseHeader(message, name string) string {
+	// SSDP messages may have multiline headers, split and search each line
+	for _, line := range strings.Split(message, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if found && strings.EqualFold(strings.TrimSpace(key), name) {
+			return strings.TrimSpace(value)
 		}
 	}
 	return ""
@@ -0,0 +1,176 @@
+package redfish
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startSSDPResponder answers each M-SEARCH on conn with the given number of
+// identical responses
+func startSSDPResponder(t *testing.T, conn *net.UDPConn, alURI string, repeat int) {
+	t.Helper()
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buffer := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFromUDP(buffer)
+			if err != nil {
+				return
+			}
+			if !strings.HasPrefix(string(buffer[:n]), "M-SEARCH") {
+				continue
+			}
+			response := "HTTP/1.1 200 OK\r\n" +
+				"ST: " + ssdpST + "\r\n" +
+				"AL: " + alURI + "\r\n\r\n"
+			for range repeat {
+				conn.WriteToUDP([]byte(response), addr)
+			}
+		}
+	}()
+}
+
+func newTestDiscovery() *SSDPDiscovery {
+	return NewSSDPDiscovery(200*time.Millisecond, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestDiscoverMergesRepeatedResponses(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	startSSDPResponder(t, conn, "https://127.0.0.1/redfish/v1/", 3)
+
+	discovery := newTestDiscovery()
+	discovery.SetTarget(conn.LocalAddr().(*net.UDPAddr))
+
+	hosts, err := discovery.DiscoverContext(context.Background())
+	if err != nil {
+		t.Fatalf("DiscoverContext failed: %v", err)
+	}
+	if len(hosts) != 1 {
+		t.Fatalf("Expected 1 host, got %+v", hosts)
+	}
+	if hosts[0].Address != "127.0.0.1" || hosts[0].ServiceRoot != "https://127.0.0.1/redfish/v1/" {
+		t.Errorf("Unexpected host %+v", hosts[0])
+	}
+}
+
+func TestDiscoverIPv6(t *testing.T) {
+	conn, err := net.ListenUDP("udp6", &net.UDPAddr{IP: net.IPv6loopback})
+	if err != nil {
+		t.Skipf("IPv6 loopback unavailable: %v", err)
+	}
+	startSSDPResponder(t, conn, "https://[::1]/redfish/v1/", 1)
+
+	discovery := newTestDiscovery()
+	discovery.SetTarget(conn.LocalAddr().(*net.UDPAddr))
+
+	hosts, err := discovery.DiscoverContext(context.Background())
+	if err != nil {
+		t.Fatalf("DiscoverContext failed: %v", err)
+	}
+	if len(hosts) != 1 || hosts[0].Address != "::1" {
+		t.Fatalf("Expected host ::1, got %+v", hosts)
+	}
+}
+
+func TestDiscoverUnknownInterface(t *testing.T) {
+	discovery := newTestDiscovery()
+	discovery.SetInterfaces([]string{"no-such-nic0"})
+
+	if _, err := discovery.DiscoverContext(context.Background()); err == nil {
+		t.Fatal("Expected an error when no interface matches")
+	}
+}
+
+func TestSSDPSearchesPinInterface(t *testing.T) {
+	iface := net.Interface{Name: "eth1"}
+
+	v4 := ipv4Search(iface, net.ParseIP("192.0.2.5"))
+	if v4.network != "udp4" || !v4.local.IP.Equal(net.ParseIP("192.0.2.5")) {
+		t.Fatalf("Unexpected IPv4 search %+v", v4)
+	}
+	if len(v4.groups) != 1 || v4.groups[0].String() != "239.255.255.250:1900" {
+		t.Errorf("Unexpected IPv4 groups %v", v4.groups)
+	}
+
+	v6 := ipv6Search(iface, net.ParseIP("fe80::1"))
+	if v6.network != "udp6" || v6.local.Zone != "eth1" {
+		t.Fatalf("Unexpected IPv6 search %+v", v6)
+	}
+	var groups []string
+	for _, group := range v6.groups {
+		groups = append(groups, group.String())
+	}
+	if got := strings.Join(groups, " "); got != "[ff02::c%eth1]:1900 [ff05::c%eth1]:1900" {
+		t.Errorf("Unexpected IPv6 groups %s", got)
+	}
+}
+
+func TestHostAddressKeepsLinkLocalZone(t *testing.T) {
+	tests := []struct {
+		addr *net.UDPAddr
+		want string
+	}{
+		{&net.UDPAddr{IP: net.ParseIP("192.0.2.5")}, "192.0.2.5"},
+		{&net.UDPAddr{IP: net.ParseIP("fe80::1"), Zone: "eth1"}, "fe80::1%eth1"},
+		{&net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Zone: "eth1"}, "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		if got := hostAddress(tt.addr); got != tt.want {
+			t.Errorf("hostAddress(%v) = %s, want %s", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestParseResponse(t *testing.T) {
+	discovery := newTestDiscovery()
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	host, ok := discovery.parseResponse("HTTP/1.1 200 OK\r\n"+
+		"Cache-Control: no-cache=\"Ext\", max-age = 600\r\n"+
+		"USN: uuid:1a2b3c4d-0000-1111-2222-333344445555::urn:dmtf-org:service:redfish-rest:1:6\r\n"+
+		"AL: https://10.0.0.5:8443/redfish/v1/\r\n\r\n", now)
+	if !ok {
+		t.Fatal("Expected a valid response")
+	}
+	if host.Port != 8443 {
+		t.Errorf("Expected port 8443, got %d", host.Port)
+	}
+	if host.UUID != "1a2b3c4d-0000-1111-2222-333344445555" {
+		t.Errorf("Unexpected UUID %q", host.UUID)
+	}
+	if !strings.HasPrefix(host.USN, "uuid:1a2b3c4d") {
+		t.Errorf("Unexpected USN %q", host.USN)
+	}
+	if host.MaxAge != 600*time.Second || !host.ExpiresAt.Equal(now.Add(600*time.Second)) {
+		t.Errorf("Unexpected lifetime %s until %s", host.MaxAge, host.ExpiresAt)
+	}
+
+	host, ok = discovery.parseResponse("HTTP/1.1 200 OK\r\nal: https://bmc.example.com/redfish/v1\r\n\r\n", now)
+	if !ok {
+		t.Fatal("Expected a valid response")
+	}
+	if host.Port != 443 || host.USN != "" || host.MaxAge != 0 {
+		t.Errorf("Unexpected defaults %+v", host)
+	}
+	if !host.ExpiresAt.Equal(now.Add(DefaultSSDPMaxAge)) {
+		t.Errorf("Expected default lifetime, expires at %s", host.ExpiresAt)
+	}
+
+	host, ok = discovery.parseResponse("HTTP/1.1 200 OK\r\nCACHE-CONTROL: max-age=0\r\nAL: https://10.0.0.5/redfish/v1/\r\n\r\n", now)
+	if !ok || host.MaxAge != 0 || !host.ExpiresAt.Equal(now.Add(DefaultSSDPMaxAge)) {
+		t.Errorf("Expected max-age=0 to get the default lifetime, got %+v (ok=%v)", host, ok)
+	}
+
+	if _, ok := discovery.parseResponse("HTTP/1.1 200 OK\r\nAL: http://10.0.0.5/redfish/v1/\r\n\r\n", now); ok {
+		t.Error("Expected a plain HTTP service root to be rejected")
+	}
+}
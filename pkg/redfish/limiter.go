@@ -0,0 +1,118 @@
+package redfish
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrQueueTimeout is returned when a request waited too long for a free
+// request slot
+var ErrQueueTimeout = errors.New("timed out waiting for a request slot")
+
+// Limiter bounds the number of concurrent requests to one BMC. Requests
+// beyond the limit wait in FIFO order. A Limiter is shared by all clients of
+// the same host and is safe for concurrent use.
+type Limiter struct {
+	maxInFlight  int
+	queueTimeout time.Duration
+
+	mu       sync.Mutex
+	inFlight int
+	// waiters holds a channel per queued request, closed when the request
+	// is handed a slot
+	waiters list.List
+}
+
+// NewLimiter creates a limiter allowing maxInFlight concurrent requests.
+// queueTimeout bounds how long a request waits for a slot; zero waits until
+// the request's context ends.
+func NewLimiter(maxInFlight int, queueTimeout time.Duration) *Limiter {
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+	return &Limiter{
+		maxInFlight:  maxInFlight,
+		queueTimeout: queueTimeout,
+	}
+}
+
+// Acquire waits for a request slot. The returned function releases it and
+// must be called exactly once.
+func (l *Limiter) Acquire(ctx context.Context) (func(), error) {
+	l.mu.Lock()
+	if l.inFlight < l.maxInFlight && l.waiters.Len() == 0 {
+		l.inFlight++
+		l.mu.Unlock()
+		return l.release, nil
+	}
+
+	ready := make(chan struct{})
+	elem := l.waiters.PushBack(ready)
+	l.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if l.queueTimeout > 0 {
+		timer := time.NewTimer(l.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var err error
+	select {
+	case <-ready:
+		return l.release, nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timeout:
+		err = fmt.Errorf("%w after %s (%d requests in flight)", ErrQueueTimeout, l.queueTimeout, l.maxInFlight)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-ready:
+		// A slot was handed over while giving up; pass it on
+		l.releaseLocked()
+	default:
+		l.waiters.Remove(elem)
+	}
+	return nil, err
+}
+
+// release frees a slot, handing it to the longest waiting request if any
+func (l *Limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.releaseLocked()
+}
+
+func (l *Limiter) releaseLocked() {
+	if front := l.waiters.Front(); front != nil {
+		// The slot moves to the waiter without changing inFlight
+		l.waiters.Remove(front)
+		close(front.Value.(chan struct{}))
+		return
+	}
+	l.inFlight--
+}
+
+// Stats returns the number of requests in flight and waiting
+func (l *Limiter) Stats() (inFlight, queued int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight, l.waiters.Len()
+}
+
+// MaxInFlight returns the configured concurrency limit
+func (l *Limiter) MaxInFlight() int {
+	return l.maxInFlight
+}
+
+// QueueTimeout returns the configured queue wait timeout
+func (l *Limiter) QueueTimeout() time.Duration {
+	return l.queueTimeout
+}
@@ -0,0 +1,137 @@
+package redfish
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitQueued waits until the limiter has n queued requests
+func waitQueued(t *testing.T, l *Limiter, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, queued := l.Stats(); queued == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d queued requests", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLimiterServesWaitersInOrder(t *testing.T) {
+	limiter := NewLimiter(1, 0)
+	release, err := limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done, err := limiter.Acquire(context.Background())
+			if err != nil {
+				t.Errorf("acquire %d failed: %v", i, err)
+				return
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			done()
+		}()
+		waitQueued(t, limiter, i+1)
+	}
+
+	release()
+	wg.Wait()
+
+	for i, got := range order {
+		if got != i {
+			t.Fatalf("Expected FIFO order, got %v", order)
+		}
+	}
+	if inFlight, queued := limiter.Stats(); inFlight != 0 || queued != 0 {
+		t.Errorf("Expected idle limiter, got %d in flight and %d queued", inFlight, queued)
+	}
+}
+
+func TestLimiterQueueTimeout(t *testing.T) {
+	limiter := NewLimiter(1, 20*time.Millisecond)
+	release, _ := limiter.Acquire(context.Background())
+
+	_, err := limiter.Acquire(context.Background())
+	if !errors.Is(err, ErrQueueTimeout) {
+		t.Fatalf("Expected queue timeout, got: %v", err)
+	}
+	if IsRetryable(err) {
+		t.Error("Expected queue timeout not to be retried")
+	}
+
+	// A cancelled waiter leaves the queue too
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := limiter.Acquire(ctx)
+		errCh <- err
+	}()
+	waitQueued(t, limiter, 1)
+	cancel()
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected cancellation, got: %v", err)
+	}
+
+	release()
+	if inFlight, queued := limiter.Stats(); inFlight != 0 || queued != 0 {
+		t.Errorf("Expected idle limiter, got %d in flight and %d queued", inFlight, queued)
+	}
+}
+
+func TestClientRespectsLimiter(t *testing.T) {
+	var current, peak atomic.Int32
+	server, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := current.Add(1)
+		defer current.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	}))
+	config.TLSServerCACert = certPEM(server)
+	config.Limiter = NewLimiter(2, 0)
+
+	client, err := NewClient(config, testLogger())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Get("/redfish/v1/Systems"); err != nil {
+				t.Errorf("Request failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got > 2 {
+		t.Errorf("Expected at most 2 concurrent requests, got %d", got)
+	}
+}
@@ -0,0 +1,102 @@
+package redfish
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MessageInfo is one entry of a Redfish @Message.ExtendedInfo array
+type MessageInfo struct {
+	MessageID         string   `json:"message_id"`
+	Message           string   `json:"message,omitempty"`
+	MessageArgs       []string `json:"message_args,omitempty"`
+	Severity          string   `json:"severity,omitempty"`
+	Resolution        string   `json:"resolution,omitempty"`
+	RelatedProperties []string `json:"related_properties,omitempty"`
+}
+
+// wireMessage is a Redfish Message object as sent by the service
+type wireMessage struct {
+	MessageID         string `json:"MessageId"`
+	Message           string `json:"Message"`
+	MessageArgs       []any  `json:"MessageArgs"`
+	Severity          string `json:"Severity"`
+	MessageSeverity   string `json:"MessageSeverity"`
+	Resolution        string `json:"Resolution"`
+	RelatedProperties []any  `json:"RelatedProperties"`
+}
+
+// errorBody is the Redfish error response payload
+type errorBody struct {
+	Error *struct {
+		Code         string        `json:"code"`
+		Message      string        `json:"message"`
+		ExtendedInfo []wireMessage `json:"@Message.ExtendedInfo"`
+	} `json:"error"`
+}
+
+// parseErrorBody extracts the error code, message and extended info from a
+// Redfish error response. ok is false when the body is not a Redfish error.
+func parseErrorBody(body []byte) (code, message string, info []MessageInfo, ok bool) {
+	var parsed errorBody
+	if err := json.Unmarshal(body, &parsed); err != nil || parsed.Error == nil {
+		return "", "", nil, false
+	}
+
+	for _, m := range parsed.Error.ExtendedInfo {
+		info = append(info, m.info())
+	}
+	return parsed.Error.Code, parsed.Error.Message, info, true
+}
+
+// info converts a wire message, preferring the newer MessageSeverity
+func (m wireMessage) info() MessageInfo {
+	severity := m.MessageSeverity
+	if severity == "" {
+		severity = m.Severity
+	}
+	return MessageInfo{
+		MessageID:         m.MessageID,
+		Message:           m.Message,
+		MessageArgs:       stringify(m.MessageArgs),
+		Severity:          severity,
+		Resolution:        m.Resolution,
+		RelatedProperties: stringify(m.RelatedProperties),
+	}
+}
+
+// stringify formats loosely typed JSON values; some services send numeric
+// message args despite the schema requiring strings
+func stringify(values []any) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make([]string, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			out[i] = s
+		} else {
+			out[i] = fmt.Sprint(v)
+		}
+	}
+	return out
+}
+
+// errorSummary builds a readable error description from a parsed error body
+func errorSummary(message string, info []MessageInfo) string {
+	parts := make([]string, 0, len(info)+1)
+	if message != "" {
+		parts = append(parts, message)
+	}
+	for _, m := range info {
+		text := m.Message
+		if text == "" {
+			text = m.MessageID
+		}
+		if text != "" && text != message {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
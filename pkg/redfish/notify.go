@@ -0,0 +1,161 @@
+package redfish
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ssdpAlive  = "ssdp:alive"
+	ssdpByebye = "ssdp:byebye"
+
+	// notifyRetryDelay is the first wait after a failed read, doubling with
+	// each consecutive failure up to notifyMaxRetryDelay
+	notifyRetryDelay    = 100 * time.Millisecond
+	notifyMaxRetryDelay = 30 * time.Second
+)
+
+// SSDPAnnouncement is an unsolicited NOTIFY from a Redfish service
+type SSDPAnnouncement struct {
+	// Alive is true for ssdp:alive and false for ssdp:byebye
+	Alive bool
+	// Host is the announced service. A byebye carries only the address,
+	// USN, UUID and interface.
+	Host DiscoveredHost
+}
+
+// Listen joins the SSDP multicast groups on the configured interfaces and
+// calls handle for every Redfish NOTIFY announcement until ctx is cancelled.
+// Calls to handle may come from several goroutines at once, and an
+// announcement may be handled more than once when several groups are joined.
+// Listen fails only when no group could be joined.
+func (d *SSDPDiscovery) Listen(ctx context.Context, handle func(SSDPAnnouncement)) error {
+	bindings, err := d.bindings()
+	if err != nil {
+		return err
+	}
+
+	var conns []*net.UDPConn
+	var ifaces []string
+	var failed []error
+	for _, binding := range bindings {
+		network := "udp4"
+		if binding.ipv6 {
+			network = "udp6"
+		}
+		for _, group := range ssdpGroups(binding.ipv6, "") {
+			conn, err := net.ListenMulticastUDP(network, binding.iface, group)
+			if err != nil {
+				d.logger.Warn("Failed to join SSDP group", "group", group.IP, "interface", binding.name(), "error", err)
+				failed = append(failed, err)
+				continue
+			}
+			iface := binding.name()
+			if err := isolateMulticast(conn, binding.ipv6); err != nil {
+				// The socket may also receive the group's traffic from other
+				// interfaces, so its announcements are left untagged
+				d.logger.Debug("Cannot restrict SSDP group to its interface", "interface", iface, "error", err)
+				iface = ""
+			}
+			conns = append(conns, conn)
+			ifaces = append(ifaces, iface)
+		}
+	}
+	if len(conns) == 0 {
+		return errors.Join(failed...)
+	}
+
+	d.logger.Info("Listening for SSDP announcements", "groups", len(conns))
+
+	var wg sync.WaitGroup
+	for i, conn := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.serveNotify(ctx, conn, ifaces[i], handle)
+		}()
+	}
+	wg.Wait()
+
+	d.logger.Info("Stopped listening for SSDP announcements")
+	return nil
+}
+
+// serveNotify reads announcements from conn until ctx is cancelled, then
+// closes conn. Read errors are retried with a growing delay so that a
+// persistently failing socket does not spin.
+func (d *SSDPDiscovery) serveNotify(ctx context.Context, conn *net.UDPConn, iface string, handle func(SSDPAnnouncement)) {
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	buffer := make([]byte, 2048)
+	delay := notifyRetryDelay
+	for {
+		n, addr, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			d.logger.Warn("Error reading SSDP announcement", "interface", iface, "error", err, "retry_in", delay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(2*delay, notifyMaxRetryDelay)
+			continue
+		}
+		delay = notifyRetryDelay
+
+		announcement, ok := d.parseNotify(string(buffer[:n]), time.Now())
+		if !ok {
+			continue
+		}
+		announcement.Host.Address = hostAddress(addr)
+		announcement.Host.Interface = iface
+
+		d.logger.Debug("Received SSDP announcement",
+			"address", announcement.Host.Address,
+			"alive", announcement.Alive,
+			"usn", announcement.Host.USN)
+		handle(announcement)
+	}
+}
+
+// parseNotify parses a Redfish NOTIFY received at now. Other SSDP traffic on
+// the group, such as searches and announcements of other services, is
+// ignored.
+func (d *SSDPDiscovery) parseNotify(message string, now time.Time) (SSDPAnnouncement, bool) {
+	if !strings.HasPrefix(message, "NOTIFY ") {
+		return SSDPAnnouncement{}, false
+	}
+	// Services may append a minor version, e.g. "...:redfish-rest:1:6"
+	nt := parseHeader(message, "NT")
+	if nt != ssdpST && !strings.HasPrefix(nt, ssdpST+":") {
+		return SSDPAnnouncement{}, false
+	}
+
+	switch parseHeader(message, "NTS") {
+	case ssdpAlive:
+		host, ok := d.parseResponse(message, now)
+		if !ok {
+			d.logger.Debug("Received SSDP alive announcement but no valid AL header found")
+			return SSDPAnnouncement{}, false
+		}
+		return SSDPAnnouncement{Alive: true, Host: host}, true
+	case ssdpByebye:
+		host := DiscoveredHost{USN: parseHeader(message, "USN")}
+		if matches := uuidRegex.FindStringSubmatch(host.USN); matches != nil {
+			host.UUID = matches[1]
+		}
+		return SSDPAnnouncement{Host: host}, true
+	default:
+		return SSDPAnnouncement{}, false
+	}
+}
@@ -0,0 +1,36 @@
+package redfish
+
+import (
+	"net"
+	"syscall"
+)
+
+// Socket options missing from package syscall
+const (
+	ipMulticastAll   = 0x31
+	ipv6MulticastAll = 0x1d
+)
+
+// isolateMulticast turns off IP_MULTICAST_ALL on conn. Linux otherwise
+// delivers a group's datagrams from every interface to each socket bound to
+// the port, so an announcement could not be tied to the interface it
+// arrived on.
+func isolateMulticast(conn *net.UDPConn, ipv6 bool) error {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	level, option := syscall.IPPROTO_IP, ipMulticastAll
+	if ipv6 {
+		level, option = syscall.IPPROTO_IPV6, ipv6MulticastAll
+	}
+
+	var sockErr error
+	if err := raw.Control(func(fd uintptr) {
+		sockErr = syscall.SetsockoptInt(int(fd), level, option, 0)
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
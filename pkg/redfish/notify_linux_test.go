@@ -0,0 +1,38 @@
+package redfish
+
+import (
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestIsolateMulticast(t *testing.T) {
+	for network, ipv6 := range map[string]bool{"udp4": false, "udp6": true} {
+		conn, err := net.ListenUDP(network, nil)
+		if err != nil {
+			t.Logf("Skipping %s: %v", network, err)
+			continue
+		}
+		defer conn.Close()
+
+		if err := isolateMulticast(conn, ipv6); err != nil {
+			t.Fatalf("isolateMulticast failed on %s: %v", network, err)
+		}
+
+		level, option := syscall.IPPROTO_IP, ipMulticastAll
+		if ipv6 {
+			level, option = syscall.IPPROTO_IPV6, ipv6MulticastAll
+		}
+		raw, err := conn.SyscallConn()
+		if err != nil {
+			t.Fatalf("Failed to get raw connection: %v", err)
+		}
+		value := -1
+		raw.Control(func(fd uintptr) {
+			value, err = syscall.GetsockoptInt(int(fd), level, option)
+		})
+		if err != nil || value != 0 {
+			t.Errorf("Expected multicast-all to be off on %s, got %d (%v)", network, value, err)
+		}
+	}
+}
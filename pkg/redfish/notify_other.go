@@ -0,0 +1,21 @@
+//go:build !linux
+
+package redfish
+
+import (
+	"errors"
+	"net"
+	"runtime"
+)
+
+// isolateMulticast reports whether conn only receives the traffic of groups
+// it joined on their own interface. BSD-derived systems already filter
+// multicast by interface; elsewhere the interface cannot be told apart.
+func isolateMulticast(conn *net.UDPConn, ipv6 bool) error {
+	switch runtime.GOOS {
+	case "darwin", "dragonfly", "freebsd", "netbsd", "openbsd":
+		return nil
+	default:
+		return errors.ErrUnsupported
+	}
+}
@@ -0,0 +1,122 @@
+package redfish
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testUSN = "uuid:1a2b3c4d-0000-1111-2222-333344445555::urn:dmtf-org:service:redfish-rest:1"
+
+func notifyMessage(nt, nts string) string {
+	return "NOTIFY * HTTP/1.1\r\n" +
+		"HOST: 239.255.255.250:1900\r\n" +
+		"CACHE-CONTROL: max-age=900\r\n" +
+		"NT: " + nt + "\r\n" +
+		"NTS: " + nts + "\r\n" +
+		"USN: " + testUSN + "\r\n" +
+		"AL: https://10.0.0.5:8443/redfish/v1/\r\n\r\n"
+}
+
+func TestParseNotify(t *testing.T) {
+	discovery := newTestDiscovery()
+	now := time.Now()
+
+	alive, ok := discovery.parseNotify(notifyMessage(ssdpST+":6", ssdpAlive), now)
+	if !ok || !alive.Alive {
+		t.Fatalf("Expected an alive announcement, got %+v (ok=%v)", alive, ok)
+	}
+	if alive.Host.Port != 8443 || alive.Host.USN != testUSN || !alive.Host.ExpiresAt.Equal(now.Add(900*time.Second)) {
+		t.Errorf("Unexpected announced host %+v", alive.Host)
+	}
+
+	renewed, ok := discovery.parseNotify(strings.Replace(notifyMessage(ssdpST, ssdpAlive), "max-age=900", "max-age=0", 1), now)
+	if !ok || !renewed.Host.ExpiresAt.Equal(now.Add(DefaultSSDPMaxAge)) {
+		t.Errorf("Expected max-age=0 to get the default lifetime, got %+v (ok=%v)", renewed, ok)
+	}
+
+	byebye, ok := discovery.parseNotify(notifyMessage(ssdpST, ssdpByebye), now)
+	if !ok || byebye.Alive || byebye.Host.USN != testUSN {
+		t.Errorf("Expected a byebye announcement, got %+v (ok=%v)", byebye, ok)
+	}
+
+	ignored := []string{
+		notifyMessage("upnp:rootdevice", ssdpAlive),
+		notifyMessage(ssdpST, "ssdp:update"),
+		"M-SEARCH * HTTP/1.1\r\nST: " + ssdpST + "\r\n\r\n",
+	}
+	for _, message := range ignored {
+		if announcement, ok := discovery.parseNotify(message, now); ok {
+			t.Errorf("Expected message to be ignored, got %+v", announcement)
+		}
+	}
+}
+
+func TestServeNotify(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	announcements := make(chan SSDPAnnouncement, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		newTestDiscovery().serveNotify(ctx, conn, "eth1", func(a SSDPAnnouncement) {
+			announcements <- a
+		})
+		close(stopped)
+	}()
+
+	sender, err := net.DialUDP("udp4", nil, conn.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer sender.Close()
+
+	for _, nts := range []string{ssdpAlive, ssdpByebye} {
+		if _, err := sender.Write([]byte(notifyMessage(ssdpST, nts))); err != nil {
+			t.Fatalf("Failed to send: %v", err)
+		}
+		select {
+		case a := <-announcements:
+			if a.Alive != (nts == ssdpAlive) || a.Host.Address != "127.0.0.1" || a.Host.Interface != "eth1" {
+				t.Errorf("Unexpected %s announcement %+v", nts, a)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("No %s announcement received", nts)
+		}
+	}
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Listener did not stop after cancellation")
+	}
+}
+
+func TestServeNotifyBacksOffOnReadErrors(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	// Every read now fails without the socket being closed
+	conn.SetReadDeadline(time.Now())
+
+	var logs bytes.Buffer
+	discovery := NewSSDPDiscovery(time.Second, slog.New(slog.NewTextHandler(&logs, nil)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	discovery.serveNotify(ctx, conn, "eth1", func(SSDPAnnouncement) {})
+
+	// Reads fail after 0, 100ms and 300ms before the context ends
+	if n := strings.Count(logs.String(), "Error reading SSDP announcement"); n < 1 || n > 4 {
+		t.Errorf("Expected a few read errors with backoff, got %d", n)
+	}
+}
@@ -0,0 +1,201 @@
+package redfish
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CertificateInfo describes the certificate presented by a BMC
+type CertificateInfo struct {
+	Subject       string    `json:"subject"`
+	Issuer        string    `json:"issuer"`
+	NotBefore     time.Time `json:"not_before"`
+	NotAfter      time.Time `json:"not_after"`
+	SelfSigned    bool      `json:"self_signed"`
+	CertSHA256    string    `json:"cert_sha256"`
+	SPKISHA256    string    `json:"spki_sha256"`
+	SPKISHA256B64 string    `json:"spki_sha256_base64"`
+}
+
+// NewCertificateInfo computes the fingerprints of a leaf certificate
+func NewCertificateInfo(cert *x509.Certificate) *CertificateInfo {
+	certSum := sha256.Sum256(cert.Raw)
+	spkiSum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	return &CertificateInfo{
+		Subject:       cert.Subject.String(),
+		Issuer:        cert.Issuer.String(),
+		NotBefore:     cert.NotBefore,
+		NotAfter:      cert.NotAfter,
+		SelfSigned:    cert.CheckSignatureFrom(cert) == nil,
+		CertSHA256:    hex.EncodeToString(certSum[:]),
+		SPKISHA256:    hex.EncodeToString(spkiSum[:]),
+		SPKISHA256B64: base64.StdEncoding.EncodeToString(spkiSum[:]),
+	}
+}
+
+// Matches reports whether the certificate or its public key hashes to pin
+func (i *CertificateInfo) Matches(pin []byte) bool {
+	encoded := hex.EncodeToString(pin)
+	return encoded == i.CertSHA256 || encoded == i.SPKISHA256
+}
+
+// FetchCertificate connects to address:port and returns the leaf certificate
+// the server presents, without verifying it. An empty serverName sends the
+// address as SNI.
+func FetchCertificate(ctx context.Context, address string, port int, serverName string, timeout time.Duration) (*CertificateInfo, error) {
+	if serverName == "" {
+		serverName = address
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: timeout},
+		Config: &tls.Config{
+			// Enrollment needs to see certificates that are not yet trusted
+			InsecureSkipVerify: true,
+			ServerName:         serverName,
+		},
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("TLS handshake with %s failed: %w", address, err)
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("%s presented no certificate", address)
+	}
+
+	return NewCertificateInfo(certs[0]), nil
+}
+
+// ParsePin decodes a SHA-256 pin given as hex (colons allowed) or base64
+func ParsePin(pin string) ([]byte, error) {
+	pin = strings.TrimSpace(pin)
+	pin = strings.TrimPrefix(pin, "sha256/")
+
+	if decoded, err := hex.DecodeString(strings.ReplaceAll(pin, ":", "")); err == nil && len(decoded) == sha256.Size {
+		return decoded, nil
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(pin); err == nil && len(decoded) == sha256.Size {
+		return decoded, nil
+	}
+
+	return nil, fmt.Errorf("pin must be a SHA-256 digest in hex or base64, got: %q", pin)
+}
+
+// matchesPin reports whether the certificate or its public key hashes to pin
+func matchesPin(cert *x509.Certificate, pin []byte) bool {
+	certSum := sha256.Sum256(cert.Raw)
+	spkiSum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return bytes.Equal(certSum[:], pin) || bytes.Equal(spkiSum[:], pin)
+}
+
+// ErrPinMismatch is returned when a BMC presents a certificate that does not
+// match its pin
+var ErrPinMismatch = errors.New("certificate does not match pinned fingerprint")
+
+// PinStore records trust-on-first-use pins, optionally persisting them to a
+// JSON file mapping "address:port" to SPKI SHA-256 hex digests
+type PinStore struct {
+	path string
+	mu   sync.Mutex
+	pins map[string]string
+}
+
+// NewPinStore creates a pin store. An empty path keeps pins in memory only.
+func NewPinStore(path string) (*PinStore, error) {
+	store := &PinStore{
+		path: path,
+		pins: make(map[string]string),
+	}
+
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pin store %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &store.pins); err != nil {
+		return nil, fmt.Errorf("invalid JSON in pin store %s: %w", path, err)
+	}
+
+	return store, nil
+}
+
+// pinKey identifies a service in the pin store. BMCs sharing an address on
+// different ports are pinned separately.
+func pinKey(address string, port int) string {
+	return net.JoinHostPort(address, strconv.Itoa(port))
+}
+
+// Get returns the recorded pin for the service at address:port
+func (s *PinStore) Get(address string, port int) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pin, ok := s.pins[pinKey(address, port)]
+	return pin, ok
+}
+
+// verify checks cert against the recorded pin, recording it on first use.
+// A pin that cannot be saved is not kept, so the next connection is treated
+// as the first again.
+func (s *PinStore) verify(address string, port int, cert *x509.Certificate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := pinKey(address, port)
+	if recorded, ok := s.pins[key]; ok {
+		pin, err := ParsePin(recorded)
+		if err != nil {
+			return fmt.Errorf("invalid recorded pin for %s: %w", key, err)
+		}
+		if !matchesPin(cert, pin) {
+			return fmt.Errorf("%w for %s (trust-on-first-use)", ErrPinMismatch, key)
+		}
+		return nil
+	}
+
+	s.pins[key] = NewCertificateInfo(cert).SPKISHA256
+	if err := s.save(); err != nil {
+		delete(s.pins, key)
+		return err
+	}
+	return nil
+}
+
+// save writes the pins to disk; callers must hold s.mu
+func (s *PinStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.pins, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pin store: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write pin store %s: %w", s.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,373 @@
+package redfish
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Expand types accepted in Query.Expand
+const (
+	// ExpandAll expands all hyperlinks, including those under Links
+	ExpandAll = "*"
+	// ExpandSubordinate expands hyperlinks that are not under Links
+	ExpandSubordinate = "."
+	// ExpandDependent expands hyperlinks under Links
+	ExpandDependent = "~"
+)
+
+// maxExpandFetches bounds the resources fetched when emulating $expand
+const maxExpandFetches = 200
+
+// Query holds the OData query options and retrieval settings of a GET
+type Query struct {
+	// Select limits the returned properties; nested properties use "/",
+	// e.g. "Status/Health"
+	Select []string
+	// Expand is ExpandAll, ExpandSubordinate or ExpandDependent
+	Expand string
+	// ExpandLevels is the depth of $expand; zero means one level
+	ExpandLevels int
+	Filter       string
+	Top          int
+	Skip         int
+
+	// FollowNextLinks merges the Members of all collection pages
+	FollowNextLinks bool
+	// MaxMembers caps the merged Members (DefaultMaxMembers when zero)
+	MaxMembers int
+}
+
+// hasODataOptions reports whether any OData query option is set
+func (q Query) hasODataOptions() bool {
+	return len(q.Select) > 0 || q.Expand != "" || q.Filter != "" || q.Top > 0 || q.Skip > 0
+}
+
+// Validate checks the query options
+func (q Query) Validate() error {
+	if q.Expand != "" && !slices.Contains([]string{ExpandAll, ExpandSubordinate, ExpandDependent}, q.Expand) {
+		return fmt.Errorf("invalid expand %q, must be one of %q, %q or %q", q.Expand, ExpandAll, ExpandSubordinate, ExpandDependent)
+	}
+	if q.ExpandLevels < 0 || q.Top < 0 || q.Skip < 0 {
+		return fmt.Errorf("expand levels, top and skip cannot be negative")
+	}
+	if q.ExpandLevels > 0 && q.Expand == "" {
+		return fmt.Errorf("expand levels require an expand type")
+	}
+	return nil
+}
+
+// ProtocolFeatures is the ProtocolFeaturesSupported object of the service
+// root
+type ProtocolFeatures struct {
+	SelectQuery  bool `json:"SelectQuery"`
+	FilterQuery  bool `json:"FilterQuery"`
+	TopSkipQuery bool `json:"TopSkipQuery"`
+	ExpandQuery  struct {
+		ExpandAll bool `json:"ExpandAll"`
+		Levels    bool `json:"Levels"`
+		Links     bool `json:"Links"`
+		NoLinks   bool `json:"NoLinks"`
+		MaxLevels int  `json:"MaxLevels"`
+	} `json:"ExpandQuery"`
+}
+
+// supportsExpand reports whether the service can perform the expansion
+func (f ProtocolFeatures) supportsExpand(expand string, levels int) bool {
+	e := f.ExpandQuery
+	switch expand {
+	case ExpandAll:
+		if !e.ExpandAll {
+			return false
+		}
+	case ExpandSubordinate:
+		if !e.NoLinks {
+			return false
+		}
+	case ExpandDependent:
+		if !e.Links {
+			return false
+		}
+	}
+	if levels > 1 {
+		return e.Levels && levels <= e.MaxLevels
+	}
+	return true
+}
+
+// featureCache holds the service's protocol features once read
+type featureCache struct {
+	mu       sync.Mutex
+	features *ProtocolFeatures
+}
+
+// ProtocolFeaturesContext returns the query features advertised by the
+// service root. The result is cached; failures are not.
+func (c *Client) ProtocolFeaturesContext(ctx context.Context) (ProtocolFeatures, error) {
+	c.features.mu.Lock()
+	defer c.features.mu.Unlock()
+
+	if c.features.features != nil {
+		return *c.features.features, nil
+	}
+
+	resp, err := c.GetContext(ctx, "/redfish/v1/")
+	if err != nil {
+		return ProtocolFeatures{}, fmt.Errorf("failed to read service root: %w", err)
+	}
+
+	var root struct {
+		ProtocolFeaturesSupported ProtocolFeatures `json:"ProtocolFeaturesSupported"`
+	}
+	if err := remarshal(resp.Data, &root); err != nil {
+		return ProtocolFeatures{}, fmt.Errorf("invalid service root: %w", err)
+	}
+
+	c.features.features = &root.ProtocolFeaturesSupported
+	return root.ProtocolFeaturesSupported, nil
+}
+
+// QueryResult is the outcome of QueryContext
+type QueryResult struct {
+	Response *RedfishResponse
+	// Truncated is set when MaxMembers stopped nextLink traversal
+	Truncated bool
+	// Emulated lists the query options applied by the client because the
+	// service does not support them
+	Emulated []string
+}
+
+// QueryContext performs a GET with OData query options. Options the service
+// supports are sent as query parameters; $select and $expand are emulated
+// otherwise. $filter, $top and $skip fail when unsupported.
+func (c *Client) QueryContext(ctx context.Context, resourcePath string, query Query) (*QueryResult, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	var params []string
+	var emulateSelect, emulateExpand bool
+	if query.hasODataOptions() {
+		features, err := c.ProtocolFeaturesContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if query.Filter != "" {
+			if !features.FilterQuery {
+				return nil, fmt.Errorf("server %s does not support $filter", c.config.Address)
+			}
+			params = append(params, "$filter="+escapeQueryValue(query.Filter))
+		}
+		if query.Top > 0 || query.Skip > 0 {
+			if !features.TopSkipQuery {
+				return nil, fmt.Errorf("server %s does not support $top and $skip", c.config.Address)
+			}
+			if query.Top > 0 {
+				params = append(params, "$top="+strconv.Itoa(query.Top))
+			}
+			if query.Skip > 0 {
+				params = append(params, "$skip="+strconv.Itoa(query.Skip))
+			}
+		}
+		if len(query.Select) > 0 {
+			if features.SelectQuery {
+				params = append(params, "$select="+escapeQueryValue(strings.Join(query.Select, ",")))
+			} else {
+				emulateSelect = true
+			}
+		}
+		if query.Expand != "" {
+			if features.supportsExpand(query.Expand, query.ExpandLevels) {
+				value := query.Expand
+				if query.ExpandLevels > 0 {
+					value += fmt.Sprintf("($levels=%d)", query.ExpandLevels)
+				}
+				params = append(params, "$expand="+escapeQueryValue(value))
+			} else {
+				emulateExpand = true
+			}
+		}
+	}
+
+	requestPath := resourcePath
+	if len(params) > 0 {
+		separator := "?"
+		if strings.Contains(requestPath, "?") {
+			separator = "&"
+		}
+		requestPath += separator + strings.Join(params, "&")
+	}
+
+	result := &QueryResult{}
+	var err error
+	if query.FollowNextLinks {
+		result.Response, result.Truncated, err = c.GetAllMembersContext(ctx, requestPath, query.MaxMembers)
+	} else {
+		result.Response, err = c.GetWithHeadersContext(ctx, requestPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if emulateExpand {
+		levels := max(query.ExpandLevels, 1)
+		budget := maxExpandFetches
+		result.Response.Data = c.expandLinks(ctx, result.Response.Data, query.Expand, levels, false, &budget)
+		result.Emulated = append(result.Emulated, "$expand")
+	}
+	if emulateSelect {
+		if data, ok := result.Response.Data.(map[string]interface{}); ok {
+			result.Response.Data = selectResource(data, query.Select)
+		}
+		result.Emulated = append(result.Emulated, "$select")
+	}
+
+	return result, nil
+}
+
+// escapeQueryValue percent-encodes a query option value. The OData
+// punctuation used in Redfish examples, such as "$levels=1" and "Status/Health",
+// is kept literal for services that match it without decoding, and spaces
+// become %20 rather than "+".
+func escapeQueryValue(value string) string {
+	var b strings.Builder
+	for _, c := range []byte(value) {
+		if isQueryLiteral(c) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// isQueryLiteral reports whether c may appear unescaped in a query value
+func isQueryLiteral(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("-._~$(),/:*'@=", c) >= 0
+}
+
+// expandLinks replaces hyperlinks in value with the resources they point to.
+// inLinks tells whether value sits under a Links property. budget bounds the
+// number of fetches; links beyond it are left as they are.
+func (c *Client) expandLinks(ctx context.Context, value interface{}, expand string, levels int, inLinks bool, budget *int) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if id, ok := referenceID(v); ok {
+			eligible := expand == ExpandAll ||
+				(expand == ExpandSubordinate && !inLinks) ||
+				(expand == ExpandDependent && inLinks)
+			if !eligible || *budget <= 0 || ctx.Err() != nil {
+				return v
+			}
+			*budget--
+
+			resp, err := c.GetContext(ctx, id)
+			if err != nil {
+				c.logger.Debug("Failed to expand hyperlink", "uri", id, "error", err)
+				return v
+			}
+			if levels > 1 {
+				return c.expandLinks(ctx, resp.Data, expand, levels-1, false, budget)
+			}
+			return resp.Data
+		}
+
+		expanded := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			expanded[key] = c.expandLinks(ctx, child, expand, levels, inLinks || key == "Links", budget)
+		}
+		return expanded
+	case []interface{}:
+		expanded := make([]interface{}, len(v))
+		for i, child := range v {
+			expanded[i] = c.expandLinks(ctx, child, expand, levels, inLinks, budget)
+		}
+		return expanded
+	default:
+		return value
+	}
+}
+
+// referenceID returns the target of an object that holds only an @odata.id
+func referenceID(object map[string]interface{}) (string, bool) {
+	if len(object) != 1 {
+		return "", false
+	}
+	id, ok := object["@odata.id"].(string)
+	return id, ok && id != ""
+}
+
+// selectResource applies a $select to a resource. On a collection the
+// selection applies to each member, and the members along with their count
+// and next link are kept.
+func selectResource(data map[string]interface{}, paths []string) map[string]interface{} {
+	members, ok := data["Members"].([]interface{})
+	if !ok {
+		return selectProperties(data, paths)
+	}
+
+	selected := selectProperties(data, nil)
+	for key, value := range data {
+		if strings.HasPrefix(key, "Members@") {
+			selected[key] = value
+		}
+	}
+
+	selectedMembers := make([]interface{}, len(members))
+	for i, member := range members {
+		if object, ok := member.(map[string]interface{}); ok {
+			member = selectProperties(object, paths)
+		}
+		selectedMembers[i] = member
+	}
+	selected["Members"] = selectedMembers
+	return selected
+}
+
+// selectProperties keeps the selected properties of a resource along with
+// its @odata annotations. Nested properties are given as "Status/Health".
+func selectProperties(data map[string]interface{}, paths []string) map[string]interface{} {
+	selected := make(map[string]interface{})
+	for key, value := range data {
+		if strings.HasPrefix(key, "@odata.") {
+			selected[key] = value
+		}
+	}
+
+	for _, path := range paths {
+		name, rest, nested := strings.Cut(strings.TrimSpace(path), "/")
+		value, ok := data[name]
+		if !ok {
+			continue
+		}
+		if !nested {
+			selected[name] = value
+			continue
+		}
+
+		child, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// Merge with other nested paths under the same property
+		existing, _ := selected[name].(map[string]interface{})
+		subset := selectProperties(child, []string{rest})
+		if existing != nil {
+			for key, value := range subset {
+				existing[key] = value
+			}
+			continue
+		}
+		selected[name] = subset
+	}
+
+	return selected
+}
@@ -0,0 +1,183 @@
+package redfish
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// newQueryBMC serves a service root advertising features and a system with
+// subordinate and related links. It returns the client config and a function
+// reporting the raw query of the last system request.
+func newQueryBMC(t *testing.T, features string) (*ClientConfig, func() string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var lastQuery string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /redfish/v1/{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"@odata.id": "/redfish/v1/", "ProtocolFeaturesSupported": ` + features + `}`))
+	})
+	mux.HandleFunc("GET /redfish/v1/Systems/1", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lastQuery = r.URL.RawQuery
+		mu.Unlock()
+		w.Write([]byte(`{
+			"@odata.id": "/redfish/v1/Systems/1",
+			"Name": "System",
+			"Status": {"Health": "OK", "State": "Enabled"},
+			"Bios": {"@odata.id": "/redfish/v1/Systems/1/Bios"},
+			"Links": {"Chassis": [{"@odata.id": "/redfish/v1/Chassis/1"}]}
+		}`))
+	})
+	mux.HandleFunc("GET /redfish/v1/Systems", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"@odata.id": "/redfish/v1/Systems",
+			"Name": "Computer System Collection",
+			"Members@odata.count": 1,
+			"Members": [{
+				"@odata.id": "/redfish/v1/Systems/1",
+				"Name": "System",
+				"Status": {"Health": "OK", "State": "Enabled"},
+				"PowerState": "On"
+			}]
+		}`))
+	})
+	mux.HandleFunc("GET /redfish/v1/Systems/1/Bios", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"@odata.id": "/redfish/v1/Systems/1/Bios", "Id": "Bios"}`))
+	})
+	mux.HandleFunc("GET /redfish/v1/Chassis/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"@odata.id": "/redfish/v1/Chassis/1", "Id": "Chassis"}`))
+	})
+
+	server, config := newTestBMC(t, mux)
+	config.TLSServerCACert = certPEM(server)
+	return config, func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return lastQuery
+	}
+}
+
+func newQueryClient(t *testing.T, config *ClientConfig) *Client {
+	t.Helper()
+	client, err := NewClient(config, testLogger())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestQuerySendsSupportedOptions(t *testing.T) {
+	config, lastQuery := newQueryBMC(t, `{
+		"SelectQuery": true, "FilterQuery": true, "TopSkipQuery": true,
+		"ExpandQuery": {"ExpandAll": true, "Levels": true, "Links": true, "NoLinks": true, "MaxLevels": 2}
+	}`)
+	client := newQueryClient(t, config)
+
+	result, err := client.QueryContext(context.Background(), "/redfish/v1/Systems/1", Query{
+		Select:       []string{"Name", "Status/Health"},
+		Expand:       ExpandSubordinate,
+		ExpandLevels: 2,
+		Filter:       "Severity eq 'Critical'",
+		Top:          5,
+		Skip:         10,
+	})
+	if err != nil {
+		t.Fatalf("QueryContext failed: %v", err)
+	}
+
+	want := "$filter=Severity%20eq%20'Critical'&$top=5&$skip=10&$select=Name,Status/Health&$expand=.($levels=2)"
+	if got := lastQuery(); got != want {
+		t.Errorf("Unexpected query\n got: %s\nwant: %s", got, want)
+	}
+	if len(result.Emulated) != 0 {
+		t.Errorf("Expected nothing emulated, got %v", result.Emulated)
+	}
+}
+
+func TestQueryEmulatesSelectAndExpand(t *testing.T) {
+	config, lastQuery := newQueryBMC(t, `{}`)
+	client := newQueryClient(t, config)
+
+	result, err := client.QueryContext(context.Background(), "/redfish/v1/Systems/1", Query{
+		Select: []string{"Name", "Status/Health", "Bios", "Links"},
+		Expand: ExpandSubordinate,
+	})
+	if err != nil {
+		t.Fatalf("QueryContext failed: %v", err)
+	}
+	if got := lastQuery(); got != "" {
+		t.Errorf("Expected no query parameters, got %q", got)
+	}
+	if !reflect.DeepEqual(result.Emulated, []string{"$expand", "$select"}) {
+		t.Errorf("Expected $expand and $select to be emulated, got %v", result.Emulated)
+	}
+
+	data := result.Response.Data.(map[string]interface{})
+	if _, ok := data["@odata.id"]; !ok {
+		t.Error("Expected @odata.id to be kept")
+	}
+	if !reflect.DeepEqual(data["Status"], map[string]interface{}{"Health": "OK"}) {
+		t.Errorf("Expected only Status/Health, got %v", data["Status"])
+	}
+	if bios := data["Bios"].(map[string]interface{}); bios["Id"] != "Bios" {
+		t.Errorf("Expected subordinate Bios to be expanded, got %v", bios)
+	}
+	chassis := data["Links"].(map[string]interface{})["Chassis"].([]interface{})[0].(map[string]interface{})
+	if _, ok := chassis["Id"]; ok {
+		t.Error("Expected links not to be expanded by '.'")
+	}
+
+	result, err = client.QueryContext(context.Background(), "/redfish/v1/Systems/1", Query{Expand: ExpandDependent})
+	if err != nil {
+		t.Fatalf("QueryContext failed: %v", err)
+	}
+	data = result.Response.Data.(map[string]interface{})
+	chassis = data["Links"].(map[string]interface{})["Chassis"].([]interface{})[0].(map[string]interface{})
+	if chassis["Id"] != "Chassis" {
+		t.Errorf("Expected links to be expanded by '~', got %v", chassis)
+	}
+	if _, ok := data["Bios"].(map[string]interface{})["Id"]; ok {
+		t.Error("Expected subordinate resources not to be expanded by '~'")
+	}
+
+	// On a collection the selection applies to each member
+	result, err = client.QueryContext(context.Background(), "/redfish/v1/Systems", Query{
+		Select: []string{"Name", "Status/Health"},
+	})
+	if err != nil {
+		t.Fatalf("QueryContext failed: %v", err)
+	}
+	data = result.Response.Data.(map[string]interface{})
+	if data["Members@odata.count"] != float64(1) || data["@odata.id"] != "/redfish/v1/Systems" {
+		t.Errorf("Expected collection annotations to be kept, got %v", data)
+	}
+	members, _ := data["Members"].([]interface{})
+	if len(members) != 1 {
+		t.Fatalf("Expected 1 member, got %v", data["Members"])
+	}
+	want := map[string]interface{}{
+		"@odata.id": "/redfish/v1/Systems/1",
+		"Name":      "System",
+		"Status":    map[string]interface{}{"Health": "OK"},
+	}
+	if !reflect.DeepEqual(members[0], want) {
+		t.Errorf("Expected selected member properties, got %v", members[0])
+	}
+}
+
+func TestQueryRejectsUnsupportedFilter(t *testing.T) {
+	config, _ := newQueryBMC(t, `{"SelectQuery": true}`)
+	client := newQueryClient(t, config)
+
+	if _, err := client.QueryContext(context.Background(), "/redfish/v1/Systems/1", Query{Filter: "Id eq '1'"}); err == nil {
+		t.Error("Expected unsupported $filter to fail")
+	}
+	if _, err := client.QueryContext(context.Background(), "/redfish/v1/Systems/1", Query{Expand: "all"}); err == nil {
+		t.Error("Expected invalid expand to fail")
+	}
+}
@@ -0,0 +1,528 @@
+package redfish
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bundledRegistryFiles holds DMTF registries used when a service does not
+// host its own copy
+//
+//go:embed registries/*.json
+var bundledRegistryFiles embed.FS
+
+// RegistryMessage is a message definition from a Redfish message registry
+type RegistryMessage struct {
+	Description     string `json:"Description"`
+	Message         string `json:"Message"`
+	Severity        string `json:"Severity"`
+	MessageSeverity string `json:"MessageSeverity"`
+	NumberOfArgs    int    `json:"NumberOfArgs"`
+	Resolution      string `json:"Resolution"`
+}
+
+// severity returns MessageSeverity, falling back to the deprecated Severity
+func (m RegistryMessage) severity() string {
+	if m.MessageSeverity != "" {
+		return m.MessageSeverity
+	}
+	return m.Severity
+}
+
+// Format returns the message text with %1 through %N replaced by args
+func (m RegistryMessage) Format(args []string) string {
+	return substituteArgs(m.Message, args)
+}
+
+// MessageRegistry is a Redfish message registry
+type MessageRegistry struct {
+	ID              string                     `json:"Id"`
+	RegistryPrefix  string                     `json:"RegistryPrefix"`
+	RegistryVersion string                     `json:"RegistryVersion"`
+	Messages        map[string]RegistryMessage `json:"Messages"`
+}
+
+// Registries is a set of message registries that expands MessageIds. It is
+// safe for concurrent use.
+type Registries struct {
+	mu sync.RWMutex
+	// byPrefix holds registries by RegistryPrefix, one per version
+	byPrefix map[string][]*MessageRegistry
+}
+
+// NewRegistries creates an empty registry set
+func NewRegistries() *Registries {
+	return &Registries{byPrefix: make(map[string][]*MessageRegistry)}
+}
+
+// loadBundledRegistries parses the embedded registries once
+var loadBundledRegistries = sync.OnceValue(func() []*MessageRegistry {
+	entries, err := bundledRegistryFiles.ReadDir("registries")
+	if err != nil {
+		panic(fmt.Sprintf("failed to read bundled registries: %v", err))
+	}
+
+	var registries []*MessageRegistry
+	for _, entry := range entries {
+		data, err := bundledRegistryFiles.ReadFile(path.Join("registries", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("failed to read bundled registry %s: %v", entry.Name(), err))
+		}
+		var registry MessageRegistry
+		if err := json.Unmarshal(data, &registry); err != nil {
+			panic(fmt.Sprintf("failed to parse bundled registry %s: %v", entry.Name(), err))
+		}
+		registries = append(registries, &registry)
+	}
+	return registries
+})
+
+// BundledRegistries returns a registry set holding the bundled DMTF Base,
+// Task and ResourceEvent registries
+func BundledRegistries() *Registries {
+	registries := NewRegistries()
+	for _, registry := range loadBundledRegistries() {
+		registries.Add(registry)
+	}
+	return registries
+}
+
+// Add adds a registry, replacing any registry with the same prefix and
+// version
+func (r *Registries) Add(registry *MessageRegistry) {
+	prefix := registry.RegistryPrefix
+	if prefix == "" {
+		prefix, _, _ = strings.Cut(registry.ID, ".")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions := slices.DeleteFunc(r.byPrefix[prefix], func(existing *MessageRegistry) bool {
+		return existing.RegistryVersion == registry.RegistryVersion
+	})
+	r.byPrefix[prefix] = append(versions, registry)
+}
+
+// Lookup finds the definition of a MessageId such as
+// "Base.1.8.PropertyValueNotInList". The registry matching the MessageId's
+// major and minor version is preferred, then the newest registry of the same
+// major version, then any version that defines the message.
+func (r *Registries) Lookup(messageID string) (RegistryMessage, bool) {
+	prefix, version, key, ok := splitMessageID(messageID)
+	if !ok {
+		return RegistryMessage{}, false
+	}
+
+	r.mu.RLock()
+	candidates := slices.Clone(r.byPrefix[prefix])
+	r.mu.RUnlock()
+
+	slices.SortStableFunc(candidates, func(a, b *MessageRegistry) int {
+		return compareRegistryPreference(version, b.RegistryVersion, a.RegistryVersion)
+	})
+
+	for _, registry := range candidates {
+		if message, ok := registry.Messages[key]; ok {
+			return message, true
+		}
+	}
+	return RegistryMessage{}, false
+}
+
+// Expand returns the human-readable text of a MessageId with its arguments
+// substituted
+func (r *Registries) Expand(messageID string, args []string) (string, bool) {
+	message, ok := r.Lookup(messageID)
+	if !ok {
+		return "", false
+	}
+	return message.Format(args), true
+}
+
+// ExpandInfo fills the message text, severity and resolution of an extended
+// info entry from its registry definition. Values sent by the service are
+// kept.
+func (r *Registries) ExpandInfo(info MessageInfo) MessageInfo {
+	message, ok := r.Lookup(info.MessageID)
+	if !ok {
+		return info
+	}
+	return expandInfo(info, message)
+}
+
+// expandInfo fills the empty fields of info from its registry definition
+func expandInfo(info MessageInfo, message RegistryMessage) MessageInfo {
+	if info.Message == "" {
+		info.Message = message.Format(info.MessageArgs)
+	}
+	if info.Severity == "" {
+		info.Severity = message.severity()
+	}
+	if info.Resolution == "" {
+		info.Resolution = message.Resolution
+	}
+	return info
+}
+
+// splitMessageID splits "Prefix.Major.Minor[.Errata].Key" into its parts.
+// The version is empty for MessageIds without one.
+func splitMessageID(messageID string) (prefix, version, key string, ok bool) {
+	parts := strings.Split(messageID, ".")
+	if len(parts) < 2 || parts[0] == "" || parts[len(parts)-1] == "" {
+		return "", "", "", false
+	}
+	return parts[0], strings.Join(parts[1:len(parts)-1], "."), parts[len(parts)-1], true
+}
+
+// compareRegistryPreference orders registry versions a and b by how well
+// they match the wanted MessageId version: same major.minor first, then same
+// major, then newest
+func compareRegistryPreference(want, a, b string) int {
+	wantParts := versionParts(want)
+	score := func(version string) int {
+		parts := versionParts(version)
+		switch {
+		case len(wantParts) >= 2 && len(parts) >= 2 && parts[0] == wantParts[0] && parts[1] == wantParts[1]:
+			return 2
+		case len(wantParts) >= 1 && len(parts) >= 1 && parts[0] == wantParts[0]:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	if diff := score(a) - score(b); diff != 0 {
+		return diff
+	}
+	return slices.Compare(versionParts(a), versionParts(b))
+}
+
+// versionParts parses a dotted version, ignoring non-numeric segments
+func versionParts(version string) []int {
+	var parts []int
+	for _, segment := range strings.Split(version, ".") {
+		if n, err := strconv.Atoi(segment); err == nil {
+			parts = append(parts, n)
+		}
+	}
+	return parts
+}
+
+// messageArgPattern matches %1 through %N placeholders
+var messageArgPattern = regexp.MustCompile(`%(\d+)`)
+
+// substituteArgs replaces %N placeholders with the corresponding argument,
+// leaving placeholders without an argument untouched
+func substituteArgs(message string, args []string) string {
+	return messageArgPattern.ReplaceAllStringFunc(message, func(placeholder string) string {
+		n, err := strconv.Atoi(placeholder[1:])
+		if err != nil || n < 1 || n > len(args) {
+			return placeholder
+		}
+		return args[n-1]
+	})
+}
+
+// registryRequestTimeout bounds each request for a service's registries.
+// Registries only improve messages, so they are fetched with a single
+// attempt rather than holding up lookups with retries.
+const registryRequestTimeout = 10 * time.Second
+
+// indexKey identifies the fetch of the Registries collection among the
+// per-prefix fetches; MessageId prefixes are never empty
+const indexKey = ""
+
+// registryCache resolves MessageIds for one service. Registries hosted by
+// the service are fetched on first use of their prefix and preferred over
+// the bundled copies.
+type registryCache struct {
+	client  *Client
+	bundled *Registries
+
+	mu sync.Mutex
+	// index maps registry prefixes to the URIs of the service's registry
+	// files once the Registries collection has been read
+	index map[string][]string
+	// loaded holds the prefixes, and indexKey, that have been fetched
+	loaded map[string]bool
+	// fetches holds the fetches in progress by prefix
+	fetches map[string]*registryFetch
+	service *Registries
+}
+
+// registryFetch is a fetch shared by concurrent lookups
+type registryFetch struct {
+	// done is closed once the fetch has finished and err is set
+	done chan struct{}
+	err  error
+}
+
+// newRegistryCache creates a registry cache for a client
+func newRegistryCache(client *Client) *registryCache {
+	return &registryCache{
+		client:  client,
+		bundled: BundledRegistries(),
+		loaded:  make(map[string]bool),
+		fetches: make(map[string]*registryFetch),
+		service: NewRegistries(),
+	}
+}
+
+// lookup finds a MessageId, fetching the service's registry for its prefix
+// if it has not been loaded yet. The error is that of the fetch, in which
+// case the bundled registries were consulted.
+func (rc *registryCache) lookup(ctx context.Context, messageID string) (RegistryMessage, bool, error) {
+	prefix, _, _, ok := splitMessageID(messageID)
+	if !ok {
+		return RegistryMessage{}, false, nil
+	}
+
+	err := rc.load(ctx, prefix)
+	if message, ok := rc.service.Lookup(messageID); ok {
+		return message, true, err
+	}
+	message, ok := rc.bundled.Lookup(messageID)
+	return message, ok, err
+}
+
+// load fetches the service's registries for prefix once. Failures are
+// logged and not retried for the lifetime of the client, so a service
+// without registries falls back to the bundled copies without repeated
+// requests. It returns the error of the fetch it waited for, or ctx's error
+// if ctx ends first.
+func (rc *registryCache) load(ctx context.Context, prefix string) error {
+	if err := rc.fetchOnce(ctx, indexKey, rc.fetchIndex); err != nil {
+		return err
+	}
+	return rc.fetchOnce(ctx, prefix, func(ctx context.Context) error {
+		return rc.fetchPrefix(ctx, prefix)
+	})
+}
+
+// fetchOnce runs fetch for key unless it has already run, sharing a fetch in
+// progress with concurrent callers. The fetch runs without rc.mu held and
+// outlives callers that stop waiting for it.
+func (rc *registryCache) fetchOnce(ctx context.Context, key string, fetch func(context.Context) error) error {
+	rc.mu.Lock()
+	if rc.loaded[key] {
+		rc.mu.Unlock()
+		return nil
+	}
+	f, ok := rc.fetches[key]
+	if !ok {
+		f = &registryFetch{done: make(chan struct{})}
+		rc.fetches[key] = f
+		go func() {
+			f.err = fetch(context.WithoutCancel(ctx))
+
+			rc.mu.Lock()
+			delete(rc.fetches, key)
+			rc.loaded[key] = true
+			rc.mu.Unlock()
+			close(f.done)
+		}()
+	}
+	rc.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fetchIndex reads the service's Registries collection
+func (rc *registryCache) fetchIndex(ctx context.Context) error {
+	index, err := rc.client.listRegistries(ctx)
+	if err != nil {
+		rc.client.logger.Debug("Message registries unavailable, using bundled registries", "error", err)
+		index = map[string][]string{}
+	}
+
+	rc.mu.Lock()
+	rc.index = index
+	rc.mu.Unlock()
+	return err
+}
+
+// fetchPrefix downloads the service's registries for prefix
+func (rc *registryCache) fetchPrefix(ctx context.Context, prefix string) error {
+	rc.mu.Lock()
+	uris := rc.index[prefix]
+	rc.mu.Unlock()
+
+	var errs []error
+	for _, uri := range uris {
+		registry, err := rc.client.fetchRegistry(ctx, uri)
+		if err != nil {
+			rc.client.logger.Debug("Failed to fetch message registry", "uri", uri, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		rc.service.Add(registry)
+	}
+	return errors.Join(errs...)
+}
+
+// registryFile is the subset of a MessageRegistryFile resource in use
+type registryFile struct {
+	ID       string `json:"Id"`
+	Registry string `json:"Registry"`
+	Location []struct {
+		Language string `json:"Language"`
+		URI      string `json:"Uri"`
+	} `json:"Location"`
+}
+
+// listRegistries reads the service's Registries collection and returns the
+// local registry URIs by prefix. Registries only published externally are
+// skipped.
+func (c *Client) listRegistries(ctx context.Context) (map[string][]string, error) {
+	resp, err := c.getRegistryResource(ctx, "/redfish/v1/Registries")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message registries: %w", err)
+	}
+
+	index := make(map[string][]string)
+	for _, member := range collectionMembers(resp.Data) {
+		fileResp, err := c.getRegistryResource(ctx, member)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			c.logger.Debug("Failed to read message registry file", "uri", member, "error", err)
+			continue
+		}
+
+		var file registryFile
+		if err := remarshal(fileResp.Data, &file); err != nil {
+			c.logger.Debug("Invalid message registry file", "uri", member, "error", err)
+			continue
+		}
+
+		name := file.Registry
+		if name == "" {
+			name = file.ID
+		}
+		prefix, _, _ := strings.Cut(name, ".")
+
+		// Prefer the English copy, otherwise take the first local one
+		uri := ""
+		for _, location := range file.Location {
+			if location.URI == "" {
+				continue
+			}
+			if uri == "" || location.Language == "en" {
+				uri = location.URI
+			}
+			if location.Language == "en" {
+				break
+			}
+		}
+		if prefix != "" && uri != "" {
+			index[prefix] = append(index[prefix], uri)
+		}
+	}
+
+	return index, nil
+}
+
+// getRegistryResource reads a registry resource with a single attempt
+// bounded by registryRequestTimeout
+func (c *Client) getRegistryResource(ctx context.Context, uri string) (*RedfishResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, registryRequestTimeout)
+	defer cancel()
+	return c.doRequest(ctx, "GET", uri, nil)
+}
+
+// fetchRegistry downloads a message registry from the service
+func (c *Client) fetchRegistry(ctx context.Context, uri string) (*MessageRegistry, error) {
+	resp, err := c.getRegistryResource(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	var registry MessageRegistry
+	if err := remarshal(resp.Data, &registry); err != nil {
+		return nil, fmt.Errorf("invalid message registry: %w", err)
+	}
+	if len(registry.Messages) == 0 {
+		return nil, fmt.Errorf("message registry %s has no messages", uri)
+	}
+	return &registry, nil
+}
+
+// collectionMembers returns the @odata.id of each member of a collection
+func collectionMembers(data interface{}) []string {
+	object, _ := data.(map[string]interface{})
+	members, _ := object["Members"].([]interface{})
+
+	var ids []string
+	for _, member := range members {
+		if m, ok := member.(map[string]interface{}); ok {
+			if id, ok := m["@odata.id"].(string); ok && id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// remarshal converts decoded JSON into a typed value
+func remarshal(data interface{}, v interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// LookupMessage returns the registry definition of a MessageId, consulting
+// the service's registries before the bundled DMTF copies. It returns an
+// error when the service's registries could not be fetched; the definition
+// then comes from the bundled copies, if they have it.
+func (c *Client) LookupMessage(ctx context.Context, messageID string) (RegistryMessage, bool, error) {
+	return c.registries.lookup(ctx, messageID)
+}
+
+// ExpandMessage returns the human-readable text of a MessageId with its
+// arguments substituted
+func (c *Client) ExpandMessage(ctx context.Context, messageID string, args []string) (string, bool) {
+	message, ok, _ := c.LookupMessage(ctx, messageID)
+	if !ok {
+		return "", false
+	}
+	return message.Format(args), true
+}
+
+// ExpandMessages fills missing message text, severity and resolution of
+// extended info entries from the registries
+func (c *Client) ExpandMessages(ctx context.Context, infos []MessageInfo) []MessageInfo {
+	if len(infos) == 0 {
+		return infos
+	}
+
+	expanded := make([]MessageInfo, len(infos))
+	for i, info := range infos {
+		expanded[i] = info
+		if info.Message != "" && info.Severity != "" && info.Resolution != "" {
+			continue
+		}
+		if message, ok, _ := c.LookupMessage(ctx, info.MessageID); ok {
+			expanded[i] = expandInfo(info, message)
+		}
+	}
+	return expanded
+}
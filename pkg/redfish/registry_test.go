@@ -0,0 +1,240 @@
+package redfish
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubstituteArgs(t *testing.T) {
+	tests := []struct {
+		message string
+		args    []string
+		want    string
+	}{
+		{"The value %1 for the property %2 is invalid.", []string{"Sideways", "IndicatorLED"}, "The value Sideways for the property IndicatorLED is invalid."},
+		{"%2 before %1", []string{"a", "b"}, "b before a"},
+		{"Missing %1 and %2", []string{"one"}, "Missing one and %2"},
+		{"Ten %10", []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "ten"}, "Ten ten"},
+		{"No args", nil, "No args"},
+	}
+
+	for _, tt := range tests {
+		if got := substituteArgs(tt.message, tt.args); got != tt.want {
+			t.Errorf("substituteArgs(%q, %v) = %q, want %q", tt.message, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestBundledRegistries(t *testing.T) {
+	registries := BundledRegistries()
+
+	text, ok := registries.Expand("Base.1.8.PropertyValueNotInList", []string{"Sideways", "IndicatorLED"})
+	if !ok {
+		t.Fatal("Expected Base.1.8.PropertyValueNotInList to be bundled")
+	}
+	if want := "The value Sideways for the property IndicatorLED is not in the list of acceptable values."; text != want {
+		t.Errorf("Got %q, want %q", text, want)
+	}
+
+	// Common messages of the full Base registry are bundled
+	for _, id := range []string{
+		"Base.1.8.PropertyValueIncorrect",
+		"Base.1.8.QueryParameterValueFormatError",
+		"Base.1.8.CreateFailedMissingReqProperties",
+		"Base.1.8.ResourceExhaustion",
+		"Base.1.8.StringValueTooLong",
+		"Base.1.8.EventSubscriptionLimitExceeded",
+	} {
+		if _, ok := registries.Lookup(id); !ok {
+			t.Errorf("Expected %s to be bundled", id)
+		}
+	}
+	text, _ = registries.Expand("Base.1.8.StringValueTooLong", []string{"abcdef", "4"})
+	if want := "The string abcdef exceeds the length limit 4."; text != want {
+		t.Errorf("Got %q, want %q", text, want)
+	}
+
+	// Other versions of the same registry fall back to the bundled copy
+	for _, id := range []string{"Base.1.0.PropertyMissing", "Base.1.16.0.PropertyMissing", "Task.1.0.TaskStarted", "ResourceEvent.1.0.ResourceCreated"} {
+		if _, ok := registries.Lookup(id); !ok {
+			t.Errorf("Expected %s to resolve", id)
+		}
+	}
+
+	for _, id := range []string{"Base.1.8.NoSuchMessage", "Oem.1.0.Something", "NoDots", ""} {
+		if _, ok := registries.Lookup(id); ok {
+			t.Errorf("Expected %s not to resolve", id)
+		}
+	}
+
+	info := registries.ExpandInfo(MessageInfo{MessageID: "Task.1.0.TaskAborted", MessageArgs: []string{"7"}})
+	if info.Message != "The task with Id '7' has been aborted." || info.Severity != "Critical" || info.Resolution == "" {
+		t.Errorf("Unexpected expanded info: %+v", info)
+	}
+}
+
+func TestRegistryVersionPreference(t *testing.T) {
+	registries := NewRegistries()
+	for _, version := range []string{"1.0.0", "1.2.0", "1.3.1", "2.0.0"} {
+		registries.Add(&MessageRegistry{
+			RegistryPrefix:  "Test",
+			RegistryVersion: version,
+			Messages: map[string]RegistryMessage{
+				"Hello": {Message: "Hello from " + version},
+			},
+		})
+	}
+
+	tests := map[string]string{
+		"Test.1.2.Hello": "Hello from 1.2.0",
+		"Test.1.9.Hello": "Hello from 1.3.1",
+		"Test.2.0.Hello": "Hello from 2.0.0",
+		"Test.3.0.Hello": "Hello from 2.0.0",
+		"Test.Hello":     "Hello from 2.0.0",
+	}
+	for id, want := range tests {
+		if got, _ := registries.Expand(id, nil); got != want {
+			t.Errorf("Expand(%s) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestClientFetchesServiceRegistries(t *testing.T) {
+	var listings atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /redfish/v1/Registries", func(w http.ResponseWriter, r *http.Request) {
+		listings.Add(1)
+		w.Write([]byte(`{"Members": [{"@odata.id": "/redfish/v1/Registries/Oem.1.0"}, {"@odata.id": "/redfish/v1/Registries/Base.1.8"}]}`))
+	})
+	mux.HandleFunc("GET /redfish/v1/Registries/Oem.1.0", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Id": "Oem.1.0", "Registry": "Oem.1.0", "Location": [
+			{"Language": "fr", "Uri": "/registries/Oem.fr.json"},
+			{"Language": "en", "Uri": "/registries/Oem.json"}
+		]}`))
+	})
+	mux.HandleFunc("GET /registries/Oem.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Id": "Oem.1.0.0", "RegistryPrefix": "Oem", "RegistryVersion": "1.0.0", "Messages": {
+			"FanFailed": {"Message": "Fan %1 has failed.", "MessageSeverity": "Critical", "Resolution": "Replace fan %1."}
+		}}`))
+	})
+	mux.HandleFunc("GET /redfish/v1/Registries/Base.1.8", func(w http.ResponseWriter, r *http.Request) {
+		// Published externally only, so the bundled copy is used
+		w.Write([]byte(`{"Id": "Base.1.8", "Registry": "Base.1.8", "Location": [
+			{"Language": "en", "PublicationUri": "https://redfish.dmtf.org/registries/Base.1.8.1.json"}
+		]}`))
+	})
+
+	server, config := newTestBMC(t, mux)
+	config.TLSServerCACert = certPEM(server)
+
+	client, err := NewClient(config, testLogger())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	text, ok := client.ExpandMessage(ctx, "Oem.1.0.FanFailed", []string{"3"})
+	if !ok || text != "Fan 3 has failed." {
+		t.Errorf("Expected OEM message from the service, got %q (found=%v)", text, ok)
+	}
+
+	infos := client.ExpandMessages(ctx, []MessageInfo{
+		{MessageID: "Base.1.8.PropertyMissing", MessageArgs: []string{"UserName"}},
+		{MessageID: "Oem.1.0.FanFailed", MessageArgs: []string{"2"}, Message: "Fan two is gone"},
+		{MessageID: "Unknown.1.0.Thing"},
+	})
+	if infos[0].Message != "The property UserName is a required property and must be included in the request." {
+		t.Errorf("Expected bundled Base message, got %q", infos[0].Message)
+	}
+	if infos[1].Message != "Fan two is gone" || infos[1].Resolution != "Replace fan %1." {
+		t.Errorf("Expected service text kept and resolution filled, got %+v", infos[1])
+	}
+	if infos[2].Message != "" {
+		t.Errorf("Expected unknown message to stay empty, got %q", infos[2].Message)
+	}
+
+	if n := listings.Load(); n != 1 {
+		t.Errorf("Expected the registry collection to be read once, got %d", n)
+	}
+}
+
+func TestClientFallsBackWithoutServiceRegistries(t *testing.T) {
+	var requests atomic.Int32
+	server, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.NotFound(w, r)
+	}))
+	config.TLSServerCACert = certPEM(server)
+
+	client, err := NewClient(config, testLogger())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, ok := client.ExpandMessage(context.Background(), "Base.1.8.InternalError", nil); !ok {
+			t.Fatal("Expected bundled fallback")
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Expected a single registry listing attempt, got %d", n)
+	}
+}
+
+func TestRegistryFetchIsSharedAndNotRetried(t *testing.T) {
+	unblock := make(chan struct{})
+	var requests atomic.Int32
+	server, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-unblock
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	config.TLSServerCACert = certPEM(server)
+	config.MaxRetries = 3
+	config.InitialDelay = time.Millisecond
+
+	client, err := NewClient(config, testLogger())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	// A caller that gives up is not held up by the fetch in progress
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, ok := client.ExpandMessage(ctx, "Base.1.8.InternalError", nil); !ok {
+		t.Fatal("Expected bundled fallback for a caller that gave up")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, ok, err := client.LookupMessage(context.Background(), "Base.1.8.InternalError")
+			if !ok {
+				t.Error("Expected bundled fallback")
+			}
+			if Classify(err) != ErrorClassServer {
+				t.Errorf("Expected the failed fetch to be reported, got: %v", err)
+			}
+		}()
+	}
+	close(unblock)
+	wg.Wait()
+
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Expected a single registry listing attempt, got %d", n)
+	}
+
+	// Later lookups use the bundled copies without fetching again
+	if _, ok, err := client.LookupMessage(context.Background(), "Base.1.8.InternalError"); !ok || err != nil {
+		t.Errorf("Expected bundled message without error, got found=%v err=%v", ok, err)
+	}
+}
@@ -0,0 +1,62 @@
+package redfish
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// JitterMode selects how randomness is applied to retry delays
+type JitterMode string
+
+const (
+	// JitterNone uses the exponential delay as is
+	JitterNone JitterMode = "none"
+	// JitterFull picks a delay uniformly between zero and the exponential delay
+	JitterFull JitterMode = "full"
+	// JitterEqual keeps half the exponential delay and randomizes the rest
+	JitterEqual JitterMode = "equal"
+)
+
+// backoff computes retry delays from the client configuration
+type backoff struct {
+	initial time.Duration
+	max     time.Duration
+	factor  float64
+	jitter  JitterMode
+	// random returns a value in [0, 1); replaced in tests
+	random func() float64
+}
+
+// newBackoff creates a backoff from the client configuration
+func newBackoff(config *ClientConfig) *backoff {
+	factor := config.BackoffFactor
+	if factor < 1 {
+		factor = 1
+	}
+	return &backoff{
+		initial: config.InitialDelay,
+		max:     config.MaxDelay,
+		factor:  factor,
+		jitter:  config.Jitter,
+		random:  rand.Float64,
+	}
+}
+
+// delay returns the wait before retry n, where n is 0 for the first retry:
+// initial * factor^n, capped at max, with jitter applied
+func (b *backoff) delay(n uint) time.Duration {
+	d := float64(b.initial) * math.Pow(b.factor, float64(n))
+	if b.max > 0 && d > float64(b.max) {
+		d = float64(b.max)
+	}
+
+	switch b.jitter {
+	case JitterFull:
+		d = d * b.random()
+	case JitterEqual:
+		d = d/2 + d/2*b.random()
+	}
+
+	return time.Duration(d)
+}
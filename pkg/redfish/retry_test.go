@@ -0,0 +1,269 @@
+package redfish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelaySequence(t *testing.T) {
+	tests := []struct {
+		name   string
+		factor float64
+		jitter JitterMode
+		random float64
+		want   []time.Duration
+	}{
+		{
+			name:   "no jitter",
+			factor: 2,
+			jitter: JitterNone,
+			want:   []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second},
+		},
+		{
+			name:   "full jitter",
+			factor: 2,
+			jitter: JitterFull,
+			random: 0.25,
+			want:   []time.Duration{250 * time.Millisecond, 500 * time.Millisecond, 1 * time.Second, 2 * time.Second, 2500 * time.Millisecond, 2500 * time.Millisecond},
+		},
+		{
+			name:   "equal jitter",
+			factor: 2,
+			jitter: JitterEqual,
+			random: 0.5,
+			want:   []time.Duration{750 * time.Millisecond, 1500 * time.Millisecond, 3 * time.Second, 6 * time.Second, 7500 * time.Millisecond, 7500 * time.Millisecond},
+		},
+		{
+			name:   "factor below one is clamped",
+			factor: 0.5,
+			jitter: JitterNone,
+			want:   []time.Duration{1 * time.Second, 1 * time.Second, 1 * time.Second},
+		},
+		{
+			name:   "factor three",
+			factor: 3,
+			jitter: JitterNone,
+			want:   []time.Duration{1 * time.Second, 3 * time.Second, 9 * time.Second, 10 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBackoff(&ClientConfig{
+				InitialDelay:  time.Second,
+				MaxDelay:      10 * time.Second,
+				BackoffFactor: tt.factor,
+				Jitter:        tt.jitter,
+			})
+			b.random = func() float64 { return tt.random }
+
+			for n, want := range tt.want {
+				if got := b.delay(uint(n)); got != want {
+					t.Errorf("delay(%d) = %v, want %v", n, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoffJitterStaysInRange(t *testing.T) {
+	b := newBackoff(&ClientConfig{
+		InitialDelay:  time.Second,
+		MaxDelay:      time.Minute,
+		BackoffFactor: 2,
+		Jitter:        JitterEqual,
+	})
+
+	for i := 0; i < 100; i++ {
+		if got := b.delay(2); got < 2*time.Second || got > 4*time.Second {
+			t.Fatalf("Equal jitter delay %v outside [2s, 4s]", got)
+		}
+	}
+}
+
+func TestRetryDeadline(t *testing.T) {
+	server, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	config.TLSServerCACert = certPEM(server)
+	config.MaxRetries = 10
+	config.InitialDelay = 100 * time.Millisecond
+	config.BackoffFactor = 1
+	config.Jitter = JitterNone
+	config.RetryDeadline = 250 * time.Millisecond
+
+	client, err := NewClient(config, testLogger())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	start := time.Now()
+	_, err = client.GetContext(context.Background(), "/redfish/v1/Systems")
+	if err == nil || !strings.Contains(err.Error(), "exceeded retry deadline") {
+		t.Fatalf("Expected deadline error, got: %v", err)
+	}
+	// The service kept failing, which the caller did not cause
+	if class := Classify(err); class != ErrorClassServer {
+		t.Errorf("Expected the last failure's class %s, got %s", ErrorClassServer, class)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Retries ran for %v, expected to stop near the 250ms deadline", elapsed)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{" 0 ", 0},
+		{"-3", 0},
+		{"soon", 0},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		err       error
+		class     ErrorClass
+		retryable bool
+	}{
+		{&RedfishError{Code: 0}, ErrorClassNetwork, true},
+		{&RedfishError{Code: http.StatusRequestTimeout}, ErrorClassThrottled, true},
+		{&RedfishError{Code: http.StatusTooManyRequests}, ErrorClassThrottled, true},
+		{&RedfishError{Code: http.StatusServiceUnavailable}, ErrorClassThrottled, true},
+		{&RedfishError{Code: http.StatusInternalServerError}, ErrorClassServer, true},
+		{&RedfishError{Code: http.StatusNotFound}, ErrorClassClient, false},
+		{fmt.Errorf("Wrapped: %w", &RedfishError{Code: http.StatusBadRequest}), ErrorClassClient, false},
+		{context.Canceled, ErrorClassCanceled, false},
+		{errors.New("boom"), ErrorClassOther, false},
+	}
+
+	for _, tt := range tests {
+		if got := Classify(tt.err); got != tt.class {
+			t.Errorf("Classify(%v) = %s, want %s", tt.err, got, tt.class)
+		}
+		if got := IsRetryable(tt.err); got != tt.retryable {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.retryable)
+		}
+	}
+}
+
+func TestRetryAfterIsHonored(t *testing.T) {
+	var requests atomic.Int32
+	server, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"Id": "1"}`))
+	}))
+	config.TLSServerCACert = certPEM(server)
+	config.MaxRetries = 2
+	config.InitialDelay = time.Millisecond
+	config.Jitter = JitterNone
+
+	client, err := NewClient(config, testLogger())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	start := time.Now()
+	if _, err := client.GetContext(context.Background(), "/redfish/v1/Systems/1"); err != nil {
+		t.Fatalf("Expected success after throttling, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Retried after %v, expected to wait for Retry-After of 1s", elapsed)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected 2 requests, got %d", got)
+	}
+}
+
+func TestRetryAfterBeyondDeadlineFailsFast(t *testing.T) {
+	var requests atomic.Int32
+	server, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	config.TLSServerCACert = certPEM(server)
+	config.MaxRetries = 3
+
+	client, err := NewClient(config, testLogger())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.GetContext(ctx, "/redfish/v1/Systems/1")
+	if Classify(err) != ErrorClassThrottled {
+		t.Fatalf("Expected throttled error, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Waited %v for a retry that could not fit in the deadline", elapsed)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected 1 request, got %d", got)
+	}
+}
+
+func TestTruncatedBodyIsRetried(t *testing.T) {
+	var requests atomic.Int32
+	server, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) > 1 {
+			w.Write([]byte(`{"Id": "RootService"}`))
+			return
+		}
+		// Promise a longer body and drop the connection halfway
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("Failed to hijack connection: %v", err)
+			return
+		}
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 100\r\n\r\n{\"Id\": ")
+		buf.Flush()
+		conn.Close()
+	}))
+	config.TLSServerCACert = certPEM(server)
+	config.MaxRetries = 1
+	config.InitialDelay = time.Millisecond
+
+	client, err := NewClient(config, testLogger())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.GetContext(context.Background(), "/redfish/v1/"); err != nil {
+		t.Fatalf("Expected success after a retry, got: %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected 2 requests, got %d", got)
+	}
+}
@@ -0,0 +1,239 @@
+package redfish
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSweepConcurrency is the number of addresses probed at once
+	DefaultSweepConcurrency = 32
+	// DefaultSweepTimeout bounds each probe
+	DefaultSweepTimeout = 2 * time.Second
+	// MaxSweepAddresses is the largest range a sweep accepts
+	MaxSweepAddresses = 1 << 16
+
+	// maxServiceRootSize bounds the service root read from a probed address
+	maxServiceRootSize = 1 << 20
+)
+
+// SweepConfig configures a SweepDiscovery
+type SweepConfig struct {
+	// Ranges are the CIDRs to probe
+	Ranges []string
+	// Port is probed on every address, 443 when zero
+	Port int
+	// Concurrency is the number of addresses probed at once,
+	// DefaultSweepConcurrency when zero
+	Concurrency int
+	// Timeout bounds each probe, DefaultSweepTimeout when zero
+	Timeout time.Duration
+	// Lifetime is how long found hosts stay valid without being found
+	// again, DefaultSSDPMaxAge when zero
+	Lifetime time.Duration
+}
+
+// SweepDiscovery finds Redfish services by probing every address of CIDR
+// ranges for a service root, for networks where SSDP multicast is blocked
+type SweepDiscovery struct {
+	ranges      []netip.Prefix
+	port        int
+	concurrency int
+	lifetime    time.Duration
+	httpClient  *http.Client
+	logger      *slog.Logger
+}
+
+// NewSweepDiscovery creates a sweep discoverer for the configured ranges
+func NewSweepDiscovery(config SweepConfig, logger *slog.Logger) (*SweepDiscovery, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	ranges := make([]netip.Prefix, 0, len(config.Ranges))
+	for _, cidr := range config.Ranges {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid sweep range %s: %w", cidr, err)
+		}
+		if hostBits := prefix.Addr().BitLen() - prefix.Bits(); hostBits > 16 {
+			return nil, fmt.Errorf("sweep range %s exceeds %d addresses", cidr, MaxSweepAddresses)
+		}
+		ranges = append(ranges, prefix.Masked())
+	}
+
+	port := config.Port
+	if port == 0 {
+		port = 443
+	}
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultSweepConcurrency
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultSweepTimeout
+	}
+	lifetime := config.Lifetime
+	if lifetime <= 0 {
+		lifetime = DefaultSSDPMaxAge
+	}
+
+	return &SweepDiscovery{
+		ranges:      ranges,
+		port:        port,
+		concurrency: concurrency,
+		lifetime:    lifetime,
+		httpClient: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				// The service root is public and no credentials are sent;
+				// clients created for found hosts verify certificates as
+				// configured
+				TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+				DisableKeepAlives: true,
+			},
+			// A service root is served directly
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		logger: logger,
+	}, nil
+}
+
+// DiscoverContext probes every address of the ranges and returns the Redfish
+// services found. Cancelling ctx stops the sweep and returns the context's
+// error.
+func (s *SweepDiscovery) DiscoverContext(ctx context.Context) ([]DiscoveredHost, error) {
+	s.logger.Info("Starting sweep discovery", "ranges", len(s.ranges), "concurrency", s.concurrency)
+
+	addrs := make(chan netip.Addr)
+	go func() {
+		defer close(addrs)
+		for _, prefix := range s.ranges {
+			for addr := range sweepAddrs(prefix) {
+				select {
+				case addrs <- addr:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	var mu sync.Mutex
+	var hosts []DiscoveredHost
+	var wg sync.WaitGroup
+	for range s.concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for addr := range addrs {
+				host, ok := s.probe(ctx, addr)
+				if !ok {
+					continue
+				}
+				mu.Lock()
+				hosts = append(hosts, host)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	s.logger.Info("Sweep discovery completed", "hosts_found", len(hosts))
+	return hosts, nil
+}
+
+// sweepAddrs yields the addresses of a prefix, leaving out the network and
+// broadcast addresses of IPv4 subnets
+func sweepAddrs(prefix netip.Prefix) func(yield func(netip.Addr) bool) {
+	return func(yield func(netip.Addr) bool) {
+		skipEnds := prefix.Addr().Is4() && prefix.Bits() < 31
+		for addr := prefix.Addr(); addr.IsValid() && prefix.Contains(addr); addr = addr.Next() {
+			if skipEnds && (addr == prefix.Addr() || !prefix.Contains(addr.Next())) {
+				continue
+			}
+			if !yield(addr) {
+				return
+			}
+		}
+	}
+}
+
+// probe fetches the service root of addr and reports whether it is a
+// Redfish service
+func (s *SweepDiscovery) probe(ctx context.Context, addr netip.Addr) (DiscoveredHost, bool) {
+	serviceRoot := (&url.URL{
+		Scheme: "https",
+		Host:   net.JoinHostPort(addr.String(), strconv.Itoa(s.port)),
+		Path:   "/redfish/v1/",
+	}).String()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, serviceRoot, nil)
+	if err != nil {
+		return DiscoveredHost{}, false
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return DiscoveredHost{}, false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		s.logger.Debug("Sweep probe rejected (unexpected status)", "address", addr, "status", resp.StatusCode)
+		return DiscoveredHost{}, false
+	}
+
+	var root struct {
+		ODataID        string `json:"@odata.id"`
+		ODataType      string `json:"@odata.type"`
+		RedfishVersion string `json:"RedfishVersion"`
+		UUID           string `json:"UUID"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxServiceRootSize)).Decode(&root); err != nil {
+		s.logger.Debug("Sweep probe rejected (invalid JSON)", "address", addr, "error", err)
+		return DiscoveredHost{}, false
+	}
+
+	// Older services omit the versioned type but still identify the root
+	isRoot := strings.HasPrefix(root.ODataType, "#ServiceRoot.") ||
+		(root.RedfishVersion != "" && strings.TrimSuffix(root.ODataID, "/") == "/redfish/v1")
+	if !isRoot {
+		s.logger.Debug("Sweep probe rejected (not a service root)", "address", addr, "type", root.ODataType)
+		return DiscoveredHost{}, false
+	}
+
+	host := DiscoveredHost{
+		Address:     addr.String(),
+		Port:        s.port,
+		ServiceRoot: serviceRoot,
+		UUID:        root.UUID,
+		ExpiresAt:   time.Now().Add(s.lifetime),
+	}
+	s.logger.Info("Discovered Redfish endpoint",
+		"address", host.Address,
+		"port", host.Port,
+		"service_root", host.ServiceRoot,
+		"redfish_version", root.RedfishVersion)
+	return host, true
+}
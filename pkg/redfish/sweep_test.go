@@ -0,0 +1,127 @@
+package redfish
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newSweepTarget serves body as the service root over TLS on 127.0.0.1 and
+// returns the port
+func newSweepTarget(t *testing.T, body string) int {
+	t.Helper()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/redfish/v1/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return port
+}
+
+func newTestSweep(t *testing.T, port int, ranges ...string) *SweepDiscovery {
+	t.Helper()
+
+	sweep, err := NewSweepDiscovery(SweepConfig{
+		Ranges:   ranges,
+		Port:     port,
+		Timeout:  time.Second,
+		Lifetime: time.Hour,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewSweepDiscovery failed: %v", err)
+	}
+	return sweep
+}
+
+func TestSweepFindsServiceRoot(t *testing.T) {
+	port := newSweepTarget(t, `{
+		"@odata.id": "/redfish/v1/",
+		"@odata.type": "#ServiceRoot.v1_15_0.ServiceRoot",
+		"RedfishVersion": "1.15.0",
+		"UUID": "92384634-2938-2342-8820-489239905423"
+	}`)
+
+	// 127.0.0.2 refuses the connection and is skipped
+	hosts, err := newTestSweep(t, port, "127.0.0.0/30").DiscoverContext(context.Background())
+	if err != nil {
+		t.Fatalf("DiscoverContext failed: %v", err)
+	}
+	if len(hosts) != 1 {
+		t.Fatalf("Expected 1 host, got %+v", hosts)
+	}
+
+	host := hosts[0]
+	if host.Address != "127.0.0.1" || host.Port != port {
+		t.Errorf("Unexpected host %s:%d", host.Address, host.Port)
+	}
+	if host.UUID != "92384634-2938-2342-8820-489239905423" {
+		t.Errorf("Unexpected UUID %q", host.UUID)
+	}
+	if host.ServiceRoot != "https://127.0.0.1:"+strconv.Itoa(port)+"/redfish/v1/" {
+		t.Errorf("Unexpected service root %s", host.ServiceRoot)
+	}
+	if time.Until(host.ExpiresAt) < 59*time.Minute {
+		t.Errorf("Expected the configured lifetime, expires at %s", host.ExpiresAt)
+	}
+}
+
+func TestSweepRejectsOtherServices(t *testing.T) {
+	for _, body := range []string{
+		`{"status": "ok"}`,
+		`{"@odata.type": "#ComputerSystem.v1_0_0.ComputerSystem"}`,
+		`not json`,
+	} {
+		port := newSweepTarget(t, body)
+		hosts, err := newTestSweep(t, port, "127.0.0.1/32").DiscoverContext(context.Background())
+		if err != nil {
+			t.Fatalf("DiscoverContext failed: %v", err)
+		}
+		if len(hosts) != 0 {
+			t.Errorf("Expected no hosts for %s, got %+v", body, hosts)
+		}
+	}
+}
+
+func TestSweepAddrs(t *testing.T) {
+	tests := map[string][]string{
+		"10.0.0.0/30":      {"10.0.0.1", "10.0.0.2"},
+		"10.0.0.4/31":      {"10.0.0.4", "10.0.0.5"},
+		"10.0.0.9/32":      {"10.0.0.9"},
+		"fd00::/126":       {"fd00::", "fd00::1", "fd00::2", "fd00::3"},
+		"255.255.255.0/30": {"255.255.255.1", "255.255.255.2"},
+	}
+	for cidr, want := range tests {
+		var got []string
+		for addr := range sweepAddrs(netip.MustParsePrefix(cidr)) {
+			got = append(got, addr.String())
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("sweepAddrs(%s) = %v, want %v", cidr, got, want)
+		}
+	}
+}
+
+func TestNewSweepDiscoveryRejectsLargeRanges(t *testing.T) {
+	if _, err := NewSweepDiscovery(SweepConfig{Ranges: []string{"10.0.0.0/8"}}, nil); err == nil {
+		t.Error("Expected a /8 range to be rejected")
+	}
+	if _, err := NewSweepDiscovery(SweepConfig{Ranges: []string{"10.0.0.0"}}, nil); err == nil {
+		t.Error("Expected an address without prefix length to be rejected")
+	}
+}
@@ -0,0 +1,154 @@
+package redfish
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// tlsVersions maps configuration values to TLS protocol versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// buildTLSConfig creates the TLS configuration used to connect to the BMC
+func buildTLSConfig(config *ClientConfig) (*tls.Config, error) {
+	minVersion := uint16(tls.VersionTLS12)
+	if config.TLSMinVersion != "" {
+		version, ok := tlsVersions[config.TLSMinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported minimum TLS version: %s", config.TLSMinVersion)
+		}
+		minVersion = version
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         minVersion,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+		ServerName:         config.TLSServerName,
+	}
+
+	if config.TLSServerCACert != "" {
+		pool, err := loadCAPool(config.TLSServerCACert, config.TLSServerCAMerge)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.TLSClientCert != "" || config.TLSClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(config.TLSClientCert, config.TLSClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate %s: %w", config.TLSClientCert, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	} else if config.AuthMethod == AuthMethodCertificate {
+		return nil, fmt.Errorf("auth method %s requires a client certificate and key", AuthMethodCertificate)
+	}
+
+	if err := applyPinning(tlsConfig, config); err != nil {
+		return nil, err
+	}
+
+	return tlsConfig, nil
+}
+
+// applyPinning replaces chain verification with a fingerprint check when the
+// host has a pin or uses trust-on-first-use
+func applyPinning(tlsConfig *tls.Config, config *ClientConfig) error {
+	var verify func(cert *x509.Certificate) error
+
+	switch {
+	case config.TLSPinSHA256 != "":
+		pin, err := ParsePin(config.TLSPinSHA256)
+		if err != nil {
+			return err
+		}
+		verify = func(cert *x509.Certificate) error {
+			if !matchesPin(cert, pin) {
+				return fmt.Errorf("%w for %s", ErrPinMismatch, config.Address)
+			}
+			return nil
+		}
+	case config.TLSPinStore != nil:
+		verify = func(cert *x509.Certificate) error {
+			return config.TLSPinStore.verify(config.Address, config.Port, cert)
+		}
+	default:
+		return nil
+	}
+
+	// Self-signed BMC certificates cannot pass chain verification, so the pin
+	// is the only check performed
+	tlsConfig.InsecureSkipVerify = true
+	tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return fmt.Errorf("%s presented no certificate", config.Address)
+		}
+		return verify(state.PeerCertificates[0])
+	}
+
+	return nil
+}
+
+// loadCAPool builds a root pool from a PEM bundle given either as a file path
+// or inline. When merge is set the bundle is added to the system roots,
+// otherwise it replaces them.
+func loadCAPool(caCert string, merge bool) (*x509.CertPool, error) {
+	source := "inline CA bundle"
+	pemData := []byte(caCert)
+	if !strings.Contains(caCert, "-----BEGIN") {
+		source = fmt.Sprintf("CA bundle %s", caCert)
+		data, err := os.ReadFile(caCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		pemData = data
+	}
+
+	pool := x509.NewCertPool()
+	if merge {
+		systemPool, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load system root certificates: %w", err)
+		}
+		pool = systemPool
+	}
+
+	// Parse each block so that a corrupt or truncated certificate fails
+	// loudly instead of being skipped
+	count := 0
+	rest := pemData
+	for block := 1; ; block++ {
+		var p *pem.Block
+		p, rest = pem.Decode(rest)
+		if p == nil {
+			if bytes.Contains(rest, []byte("-----BEGIN")) {
+				return nil, fmt.Errorf("malformed PEM block %d in %s", block, source)
+			}
+			break
+		}
+		if p.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(p.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate in PEM block %d of %s: %w", block, source, err)
+		}
+		pool.AddCert(cert)
+		count++
+	}
+
+	if count == 0 {
+		return nil, fmt.Errorf("no valid PEM certificates found in %s", source)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,334 @@
+package redfish
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// newTestBMC starts a TLS server and returns a client config pointing at it
+func newTestBMC(t *testing.T, handler http.Handler) (*httptest.Server, *ClientConfig) {
+	t.Helper()
+
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	config := DefaultClientConfig()
+	config.Address = host
+	config.Port = port
+	config.AuthMethod = AuthMethodBasic
+	config.MaxRetries = 0
+	return server, config
+}
+
+func certPEM(server *httptest.Server) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+}
+
+func TestCustomCABundle(t *testing.T) {
+	server, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Id": "RootService"}`))
+	}))
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte(certPEM(server)), 0o600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	for name, caCert := range map[string]string{"file": caFile, "inline": certPEM(server)} {
+		for _, merge := range []bool{false, true} {
+			config.TLSServerCACert = caCert
+			config.TLSServerCAMerge = merge
+
+			client, err := NewClient(config, testLogger())
+			if err != nil {
+				t.Fatalf("%s (merge=%v): NewClient failed: %v", name, merge, err)
+			}
+			if _, err := client.Get("/redfish/v1/"); err != nil {
+				t.Errorf("%s (merge=%v): request failed: %v", name, merge, err)
+			}
+		}
+	}
+
+	// Without the bundle the test server's certificate is untrusted
+	config.TLSServerCACert = ""
+	client, err := NewClient(config, testLogger())
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err := client.Get("/redfish/v1/"); err == nil {
+		t.Error("Expected request to fail without custom CA")
+	}
+}
+
+func TestInvalidCABundle(t *testing.T) {
+	config := DefaultClientConfig()
+
+	config.TLSServerCACert = filepath.Join(t.TempDir(), "missing.pem")
+	if _, err := NewClient(config, testLogger()); err == nil || !strings.Contains(err.Error(), "failed to read") {
+		t.Errorf("Expected read error for missing bundle, got %v", err)
+	}
+
+	config.TLSServerCACert = "-----BEGIN CERTIFICATE-----\ngarbage\n-----END CERTIFICATE-----\n"
+	if _, err := NewClient(config, testLogger()); err == nil || !strings.Contains(err.Error(), "malformed PEM block 1") {
+		t.Errorf("Expected parse error for invalid bundle, got %v", err)
+	}
+
+	config.TLSServerCACert = "no certificates here"
+	if _, err := NewClient(config, testLogger()); err == nil {
+		t.Error("Expected error for a bundle without certificates")
+	}
+}
+
+func TestCABundleWithCorruptCertificate(t *testing.T) {
+	server, _ := newTestBMC(t, http.NotFoundHandler())
+
+	// A valid certificate followed by one whose DER is cut short
+	corrupt := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw[:64]})
+	bundle := filepath.Join(t.TempDir(), "bundle.pem")
+	if err := os.WriteFile(bundle, append([]byte(certPEM(server)), corrupt...), 0o600); err != nil {
+		t.Fatalf("Failed to write bundle: %v", err)
+	}
+
+	config := DefaultClientConfig()
+	config.TLSServerCACert = bundle
+	_, err := NewClient(config, testLogger())
+	if err == nil || !strings.Contains(err.Error(), "PEM block 2") || !strings.Contains(err.Error(), bundle) {
+		t.Errorf("Expected error naming %s and block 2, got %v", bundle, err)
+	}
+}
+
+// writeClientCert writes a self-signed client certificate and key to dir
+func writeClientCert(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "redfish-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile
+}
+
+func TestCertificateAuth(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "redfish-client" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("Authorization") != "" || r.Header.Get("X-Auth-Token") != "" {
+			t.Error("Expected no credentials headers with certificate auth")
+		}
+		w.Write([]byte(`{"Id": "RootService"}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	config := DefaultClientConfig()
+	config.Address = host
+	config.Port = port
+	config.MaxRetries = 0
+	config.AuthMethod = AuthMethodCertificate
+	config.Username = "ignored"
+	config.Password = "ignored"
+	config.TLSServerCACert = certPEM(server)
+
+	if _, err := NewClient(config, testLogger()); err == nil {
+		t.Fatal("Expected error for certificate auth without a client certificate")
+	}
+
+	config.TLSClientCert, config.TLSClientKey = writeClientCert(t, t.TempDir())
+	client, err := NewClient(config, testLogger())
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if err := client.Login(); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if _, err := client.Get("/redfish/v1/"); err != nil {
+		t.Fatalf("Request with client certificate failed: %v", err)
+	}
+}
+
+func TestCertificatePinning(t *testing.T) {
+	server, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	info := NewCertificateInfo(server.Certificate())
+
+	fetched, err := FetchCertificate(t.Context(), config.Address, config.Port, "", time.Second)
+	if err != nil {
+		t.Fatalf("FetchCertificate failed: %v", err)
+	}
+	if fetched.SPKISHA256 != info.SPKISHA256 || fetched.CertSHA256 != info.CertSHA256 {
+		t.Errorf("Fetched fingerprints do not match the server certificate")
+	}
+
+	wrongPin := strings.Repeat("ab", 32)
+	for pin, wantOK := range map[string]bool{
+		info.CertSHA256:                true,
+		info.SPKISHA256:                true,
+		"sha256/" + info.SPKISHA256B64: true,
+		wrongPin:                       false,
+	} {
+		config.TLSPinSHA256 = pin
+		client, err := NewClient(config, testLogger())
+		if err != nil {
+			t.Fatalf("NewClient failed for pin %s: %v", pin, err)
+		}
+		_, err = client.Get("/redfish/v1/")
+		if (err == nil) != wantOK {
+			t.Errorf("Pin %s: expected success %v, got error %v", pin, wantOK, err)
+		}
+	}
+
+	config.TLSPinSHA256 = "not-a-pin"
+	if _, err := NewClient(config, testLogger()); err == nil {
+		t.Error("Expected error for malformed pin")
+	}
+}
+
+func TestTrustOnFirstUse(t *testing.T) {
+	_, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+
+	storeFile := filepath.Join(t.TempDir(), "pins.json")
+	store, err := NewPinStore(storeFile)
+	if err != nil {
+		t.Fatalf("NewPinStore failed: %v", err)
+	}
+	config.TLSPinStore = store
+
+	for i := 0; i < 2; i++ {
+		client, err := NewClient(config, testLogger())
+		if err != nil {
+			t.Fatalf("NewClient failed: %v", err)
+		}
+		if _, err := client.Get("/redfish/v1/"); err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+	}
+
+	// The pin survives a reload from disk
+	reloaded, err := NewPinStore(storeFile)
+	if err != nil {
+		t.Fatalf("Reloading pin store failed: %v", err)
+	}
+	if _, ok := reloaded.Get(config.Address, config.Port); !ok {
+		t.Fatal("Expected pin to be persisted")
+	}
+
+	// The same address on another port is pinned separately
+	if _, ok := reloaded.Get(config.Address, config.Port+1); ok {
+		t.Error("Expected no pin for another port")
+	}
+
+	// A different certificate for the same host is rejected
+	other, _ := NewPinStore("")
+	other.pins[pinKey(config.Address, config.Port)] = strings.Repeat("cd", 32)
+	config.TLSPinStore = other
+	client, _ := NewClient(config, testLogger())
+	if _, err := client.Get("/redfish/v1/"); err == nil {
+		t.Error("Expected pin mismatch to fail")
+	}
+}
+
+func TestTrustOnFirstUseUnsavedPin(t *testing.T) {
+	_, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+
+	// The store's directory does not exist, so saving fails
+	store, err := NewPinStore(filepath.Join(t.TempDir(), "missing", "pins.json"))
+	if err != nil {
+		t.Fatalf("NewPinStore failed: %v", err)
+	}
+	config.TLSPinStore = store
+
+	client, err := NewClient(config, testLogger())
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err := client.Get("/redfish/v1/"); err == nil {
+		t.Fatal("Expected an error when the pin cannot be saved")
+	}
+	if _, ok := store.Get(config.Address, config.Port); ok {
+		t.Error("Expected unsaved pin to be discarded")
+	}
+}
+
+func TestTLSOverrides(t *testing.T) {
+	server, config := newTestBMC(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	config.TLSServerCACert = certPEM(server)
+
+	// The httptest certificate is valid for example.com
+	for serverName, wantOK := range map[string]bool{"example.com": true, "bmc.invalid": false} {
+		config.TLSServerName = serverName
+		client, err := NewClient(config, testLogger())
+		if err != nil {
+			t.Fatalf("NewClient failed: %v", err)
+		}
+		_, err = client.Get("/redfish/v1/")
+		if (err == nil) != wantOK {
+			t.Errorf("Server name %s: expected success %v, got error %v", serverName, wantOK, err)
+		}
+	}
+
+	config.TLSServerName = "bmc.invalid"
+	config.InsecureSkipVerify = true
+	client, _ := NewClient(config, testLogger())
+	if _, err := client.Get("/redfish/v1/"); err != nil {
+		t.Errorf("Expected skip-verify to accept any name, got %v", err)
+	}
+
+	config.TLSMinVersion = "1.4"
+	if _, err := NewClient(config, testLogger()); err == nil {
+		t.Error("Expected error for unsupported TLS version")
+	}
+}
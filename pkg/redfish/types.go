@@ -1,6 +1,12 @@
 package redfish
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,24 +21,48 @@ type RedfishResponse struct {
 type AuthMethod string
 
 const (
-	AuthMethodBasic   AuthMethod = "basic"
-	AuthMethodSession AuthMethod = "session"
+	AuthMethodBasic       AuthMethod = "basic"
+	AuthMethodSession     AuthMethod = "session"
+	AuthMethodCertificate AuthMethod = "certificate"
 )
 
 // ClientConfig represents configuration for a Redfish client
 type ClientConfig struct {
-	Address            string
-	Port               int
-	Username           string
-	Password           string
-	AuthMethod         AuthMethod
-	TLSServerCACert    string
+	Address    string
+	Port       int
+	Username   string
+	Password   string
+	AuthMethod AuthMethod
+	// TLSServerCACert is a PEM bundle, either a file path or inline PEM
+	TLSServerCACert string
+	// TLSServerCAMerge adds TLSServerCACert to the system roots instead of
+	// replacing them
+	TLSServerCAMerge bool
+	// TLSClientCert and TLSClientKey are PEM file paths presented to the BMC
+	// during the TLS handshake
+	TLSClientCert string
+	TLSClientKey  string
+	// TLSPinSHA256 is the expected SHA-256 of the leaf certificate or its
+	// SPKI; when set it replaces CA verification
+	TLSPinSHA256 string
+	// TLSPinStore enables trust-on-first-use pinning when TLSPinSHA256 is
+	// empty
+	TLSPinStore *PinStore
+	// TLSMinVersion is "1.0" through "1.3"; empty means TLS 1.2
+	TLSMinVersion string
+	// TLSServerName overrides the SNI and verified hostname
+	TLSServerName      string
 	InsecureSkipVerify bool
 	MaxRetries         int
 	InitialDelay       time.Duration
 	MaxDelay           time.Duration
 	BackoffFactor      float64
-	Jitter             bool
+	Jitter             JitterMode
+	// RetryDeadline bounds the total time spent on a request including
+	// retries; zero means no limit beyond the caller's context
+	RetryDeadline time.Duration
+	// Limiter bounds concurrent requests to the host; nil means unlimited
+	Limiter *Limiter
 }
 
 // DefaultClientConfig returns default client configuration
@@ -45,35 +75,157 @@ func DefaultClientConfig() *ClientConfig {
 		InitialDelay:       time.Second,
 		MaxDelay:           60 * time.Second,
 		BackoffFactor:      2.0,
-		Jitter:             true,
+		Jitter:             JitterFull,
 	}
 }
 
 // DiscoveredHost represents a host discovered via SSDP
 type DiscoveredHost struct {
-	Address     string `json:"address"`
+	Address string `json:"address"`
+	// Port is the port of the service root URI, 443 when it has none
+	Port        int    `json:"port"`
 	ServiceRoot string `json:"service_root"`
+	// USN is the unique service name, e.g.
+	// "uuid:<UUID>::urn:dmtf-org:service:redfish-rest:1"
+	USN string `json:"usn,omitempty"`
+	// UUID is the service UUID taken from the USN
+	UUID string `json:"uuid,omitempty"`
+	// MaxAge is the CACHE-CONTROL max-age of the response, zero when absent
+	// or zero
+	MaxAge time.Duration `json:"max_age,omitempty"`
+	// ExpiresAt is when the advertisement lapses unless renewed
+	ExpiresAt time.Time `json:"expires_at"`
+	// Interface is the network interface the host answered on; empty for
+	// searches via the default route and for announcements whose interface
+	// the platform cannot report
+	Interface string `json:"interface,omitempty"`
 }
 
 // RedfishError represents a Redfish-specific error
 type RedfishError struct {
 	Message string
 	Code    int
+	// RetryAfter is the wait requested by the service's Retry-After header,
+	// zero when absent
+	RetryAfter time.Duration
+	// ErrorCode is the MessageId in the body's error.code, if any
+	ErrorCode string
+	// ExtendedInfo holds the body's error.@Message.ExtendedInfo entries
+	ExtendedInfo []MessageInfo
 }
 
 func (e *RedfishError) Error() string {
 	return e.Message
 }
 
-// IsRetryable determines if an error is retryable
+// newHTTPError builds a RedfishError from an error response, using the
+// Redfish error payload when the body contains one
+func newHTTPError(statusCode int, body []byte) *RedfishError {
+	redfishErr := &RedfishError{
+		Message: fmt.Sprintf("HTTP %d: %s", statusCode, string(body)),
+		Code:    statusCode,
+	}
+
+	code, message, info, ok := parseErrorBody(body)
+	if !ok {
+		return redfishErr
+	}
+
+	redfishErr.ErrorCode = code
+	redfishErr.ExtendedInfo = info
+	if summary := errorSummary(message, info); summary != "" {
+		redfishErr.Message = fmt.Sprintf("HTTP %d: %s", statusCode, summary)
+	}
+	return redfishErr
+}
+
+// ErrorClass groups errors by how a caller should react to them
+type ErrorClass string
+
+const (
+	// ErrorClassNetwork means no HTTP response was received
+	ErrorClassNetwork ErrorClass = "network"
+	// ErrorClassThrottled means the service is busy (408, 429 or 503) and
+	// the request may succeed later
+	ErrorClassThrottled ErrorClass = "throttled"
+	// ErrorClassServer means the service failed with another 5xx status
+	ErrorClassServer ErrorClass = "server"
+	// ErrorClassClient means the request itself was rejected (4xx)
+	ErrorClassClient ErrorClass = "client"
+	// ErrorClassCanceled means the request was abandoned before it reached
+	// the service, because the caller's context ended or no request slot
+	// became free in time
+	ErrorClassCanceled ErrorClass = "canceled"
+	// ErrorClassOther covers errors that are not Redfish responses
+	ErrorClassOther ErrorClass = "other"
+)
+
+// Classify reports the class of err
+func Classify(err error) ErrorClass {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrQueueTimeout) {
+		return ErrorClassCanceled
+	}
+
+	var redfishErr *RedfishError
+	if !errors.As(err, &redfishErr) {
+		return ErrorClassOther
+	}
+
+	switch {
+	case redfishErr.Code == 0:
+		return ErrorClassNetwork
+	case redfishErr.Code == http.StatusRequestTimeout,
+		redfishErr.Code == http.StatusTooManyRequests,
+		redfishErr.Code == http.StatusServiceUnavailable:
+		return ErrorClassThrottled
+	case redfishErr.Code >= 500:
+		return ErrorClassServer
+	default:
+		return ErrorClassClient
+	}
+}
+
+// IsRetryable determines if an error is retryable. Only network, throttling
+// and server errors are; cancelled callers, rejected requests and errors that
+// are not Redfish responses don't benefit from further attempts.
 func IsRetryable(err error) bool {
-	if redfishErr, ok := err.(*RedfishError); ok {
-		// Don't retry validation errors
-		if redfishErr.Code >= 400 && redfishErr.Code < 500 {
-			return false
+	switch Classify(err) {
+	case ErrorClassNetwork, ErrorClassThrottled, ErrorClassServer:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter returns the wait requested by err's Retry-After header
+func retryAfter(err error) time.Duration {
+	var redfishErr *RedfishError
+	if errors.As(err, &redfishErr) {
+		return redfishErr.RetryAfter
+	}
+	return 0
+}
+
+// parseRetryAfter parses a Retry-After header given as delay seconds or an
+// HTTP date. It returns zero for a missing, malformed or past value.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait
 		}
 	}
 
-	// Retry network and server errors
-	return true
+	return 0
 }